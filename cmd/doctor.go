@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/nomysz/celebrations/config"
+	"github.com/nomysz/celebrations/slack"
+	"github.com/spf13/cobra"
+)
+
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:          "doctor",
+		Short:        "Verify Slack setup",
+		Long:         "Verify configured channels exist and bot is able to post there, and tokens have scopes required by enabled reminders",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := config.GetConfig()
+			if err != nil {
+				return err
+			}
+			return Preflight(c, newSlackClient(c))
+		},
+	}
+}
+
+// Preflight verifies Slack setup required by enabled reminders, returns joined problems found
+func Preflight(c *config.Config, i slack.Inspector) error {
+	var problems []error
+
+	botScopes, userScopes := getRequiredScopes(c)
+
+	if len(botScopes) > 0 {
+		granted, err := i.GetBotScopes()
+		problems = append(problems, checkScopes("bot", botScopes, granted, err)...)
+		problems = append(problems, checkChannels(c, i, granted)...)
+	}
+	if len(userScopes) > 0 {
+		granted, err := i.GetUserScopes()
+		problems = append(problems, checkScopes("user", userScopes, granted, err)...)
+	}
+
+	for _, p := range problems {
+		slog.Error("Preflight problem", "error", p)
+	}
+	if len(problems) == 0 {
+		slog.Info("Preflight passed")
+	}
+	return errors.Join(problems...)
+}
+
+// getRequiredScopes returns scopes required by enabled reminders (see Readme)
+func getRequiredScopes(c *config.Config) (bot []string, user []string) {
+	if len(getConfiguredChannels(c)) > 0 {
+		bot = append(bot, "chat:write", "channels:read", "groups:read")
+	}
+	if c.Slack.BirthdaysDirectMessageReminder.Enabled || c.Slack.AnniversariesDirectMessageReminder.Enabled || c.Slack.LeadDigest.Enabled ||
+		c.Slack.Onboarding.Enabled && (len(c.Slack.Onboarding.PreStartReminders) > 0 || len(c.Slack.Onboarding.Milestones) > 0) ||
+		c.Slack.FarewellReminder.Enabled && len(c.Slack.FarewellReminder.PreReminders) > 0 ||
+		slices.ContainsFunc(c.Slack.CustomEvents, func(ce config.CustomEvent) bool { return ce.Enabled && len(ce.PreReminders) > 0 }) {
+		bot = append(bot, "chat:write", "im:write")
+	}
+	if c.Slack.BirthdaysPersonalReminder.Enabled {
+		user = append(user, "reminders:write")
+	}
+	slices.Sort(bot)
+	return slices.Compact(bot), user
+}
+
+// getConfiguredChannels returns channels of enabled reminders mapped to reminders using them
+func getConfiguredChannels(c *config.Config) map[string][]string {
+	channels := map[string][]string{}
+	add := func(enabled bool, channel string, handler string) {
+		if enabled {
+			name := strings.TrimPrefix(channel, "#")
+			channels[name] = append(channels[name], handler)
+		}
+	}
+	add(c.Slack.AnniversaryChannelReminder.Enabled, c.Slack.AnniversaryChannelReminder.ChannelName, AnniversaryChannelHandler)
+	add(c.Slack.BirthdaysChannelReminder.Enabled, c.Slack.BirthdaysChannelReminder.ChannelName, BirthdayReminderChannelHandler)
+	add(c.Slack.MonthlyReport.Enabled, c.Slack.MonthlyReport.ChannelName, MonthlyReportHandler)
+	add(c.Slack.WeeklyDigest.Enabled, c.Slack.WeeklyDigest.ChannelName, WeeklyDigestHandler)
+	for _, ce := range c.Slack.CustomEvents {
+		add(ce.Enabled && ce.ChannelName != "", ce.ChannelName, CustomEventChannelHandler)
+	}
+	for _, ce := range c.Slack.CompanyEvents {
+		add(ce.Enabled, ce.ChannelName, CompanyEventChannelHandler)
+	}
+	add(c.Slack.Onboarding.Enabled && c.Slack.Onboarding.WelcomeChannelName != "", c.Slack.Onboarding.WelcomeChannelName, WelcomeChannelHandler)
+	add(c.Slack.FarewellReminder.Enabled && c.Slack.FarewellReminder.ChannelName != "", c.Slack.FarewellReminder.ChannelName, FarewellChannelHandler)
+	return channels
+}
+
+// checkScopes returns problems of granted token scopes, err is the one of getting them
+func checkScopes(tokenType string, required []string, granted []string, err error) []error {
+	if err != nil {
+		return []error{fmt.Errorf("Unable to verify %s token scopes: %w", tokenType, err)}
+	}
+	var problems []error
+	for _, s := range required {
+		if !slices.Contains(granted, s) {
+			problems = append(problems, fmt.Errorf("Missing %s token scope %s", tokenType, s))
+		}
+	}
+	return problems
+}
+
+// checkChannels returns problems of configured channels, botScopes are the ones granted to bot token
+func checkChannels(c *config.Config, i slack.Inspector, botScopes []string) []error {
+	configured := getConfiguredChannels(c)
+	if len(configured) == 0 {
+		return nil
+	}
+
+	channels, err := i.GetChannels()
+	if err != nil {
+		return []error{fmt.Errorf("Unable to verify channels: %w", err)}
+	}
+
+	canWritePublic := slices.Contains(botScopes, "chat:write.public")
+
+	var names []string
+	for name := range configured {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var problems []error
+	for _, name := range names {
+		idx := slices.IndexFunc(channels, func(ch slack.Channel) bool {
+			return ch.Name == name || ch.ID == name
+		})
+		if idx < 0 {
+			problems = append(problems, fmt.Errorf(
+				"Channel %s used by %s not found (private channels require bot to be added)",
+				name,
+				strings.Join(configured[name], ", "),
+			))
+			continue
+		}
+		ch := channels[idx]
+		if !ch.IsMember && (ch.IsPrivate || !canWritePublic) {
+			problems = append(problems, fmt.Errorf(
+				"Bot is not a member of channel %s used by %s",
+				name,
+				strings.Join(configured[name], ", "),
+			))
+			continue
+		}
+		slog.Debug("Channel verified", "channel", name, "channel_id", ch.ID)
+	}
+	return problems
+}
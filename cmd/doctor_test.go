@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/nomysz/celebrations/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+type TestInspector struct {
+	channels      []slack.Channel
+	botScopes     []string
+	userScopes    []string
+	botScopeCalls int
+}
+
+func (i *TestInspector) GetChannels() ([]slack.Channel, error) {
+	return i.channels, nil
+}
+
+func (i *TestInspector) GetBotScopes() ([]string, error) {
+	i.botScopeCalls++
+	return i.botScopes, nil
+}
+
+func (i *TestInspector) GetUserScopes() ([]string, error) {
+	return i.userScopes, nil
+}
+
+func TestPreflight(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	i := &TestInspector{
+		channels: []slack.Channel{
+			{ID: "C01", Name: "celebrations", IsMember: false},
+			{ID: "C02", Name: "leaders", IsPrivate: true, IsMember: true},
+		},
+		botScopes:  []string{"chat:write", "chat:write.public", "channels:read", "groups:read", "im:write"},
+		userScopes: []string{"reminders:write"},
+	}
+
+	assert.NoError(t, Preflight(getTestConfig(), i))
+	assert.Equal(t, 1, i.botScopeCalls, "Bot scopes fetched more than once")
+
+	i.channels[1].IsMember = false
+	i.botScopes = []string{"chat:write", "channels:read", "groups:read"}
+	i.userScopes = nil
+
+	err := Preflight(getTestConfig(), i)
+	assert.ErrorContains(t, err, "Bot is not a member of channel celebrations")
+	assert.ErrorContains(t, err, "Bot is not a member of channel leaders used by birthdays_channel_reminder, monthly_report")
+	assert.ErrorContains(t, err, "Missing bot token scope im:write")
+	assert.ErrorContains(t, err, "Missing user token scope reminders:write")
+
+	i.channels = nil
+	assert.ErrorContains(t, Preflight(getTestConfig(), i), "Channel leaders used by birthdays_channel_reminder, monthly_report not found")
+}
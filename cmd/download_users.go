@@ -1,107 +1,346 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/nomysz/celebrations/config"
-	"github.com/slack-go/slack"
+	"github.com/nomysz/celebrations/slack"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
-const filename = "people.yml"
+const (
+	defaultOutput = "people.yml"
+	stdoutOutput  = "-"
+)
+
+// Log progress of downloading profiles every n profiles
+const progressEvery = 100
 
 var (
-	limit         int
-	DownloadUsers = &cobra.Command{
-		Use:   "download-users",
-		Short: fmt.Sprintf("Download users from Slack"),
-		Long:  fmt.Sprintf("Get users from Slack and save as `%s` (filters out users marked as bots and deleted users).", filename),
-		Run:   func(cmd *cobra.Command, args []string) { downloadUserFromSlack() },
-	}
+	limit       int
+	concurrency int
+	output      string
+	format      string
+	flagFilters config.DownloadingUsers
 )
 
-func init() {
-	DownloadUsers.Flags().IntVarP(&limit, "limit", "l", 1000, "Limit the number of users being downloaded")
+func newDownloadUsersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "download-users",
+		Short:        fmt.Sprintf("Download users from Slack"),
+		Long:         fmt.Sprintf("Get users from Slack and save as `%s` or file given with --output in YAML (matching `people` config section), JSON or CSV format (filters out users marked as bots and deleted users, users who have left according to `leave_date` of config people or leave date custom field, and users excluded by filters from config `slack.downloading_users` or flags). Users whose profiles failed to download are skipped and reported.", defaultOutput),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !slices.Contains([]string{"yaml", "json", "csv"}, format) {
+				return fmt.Errorf("Invalid format %q, use yaml, json or csv", format)
+			}
+			c, err := config.GetConfig()
+			if err != nil {
+				return err
+			}
+			return downloadUserFromSlack(cmd.Context(), c, newSlackClient(c))
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "l", 1000, "Limit the number of users being downloaded, applied after all filters (0 for no limit)")
+	cmd.Flags().IntVarP(&concurrency, "concurrency", "c", 4, "Number of user profiles downloaded concurrently")
+	cmd.Flags().StringVarP(&output, "output", "o", defaultOutput, "Output file path, use - for stdout")
+	cmd.Flags().StringVarP(&format, "format", "f", "yaml", "Output format: yaml, json or csv")
+	cmd.Flags().BoolVar(&flagFilters.ExcludeGuests, "exclude-guests", false, "Filter out guests (multi-channel and single-channel)")
+	cmd.Flags().BoolVar(&flagFilters.RequireCustomFields, "require-custom-fields", false, "Filter out users without birth date or join date custom fields set")
+	cmd.Flags().StringSliceVar(&flagFilters.IncludeEmailDomains, "include-email-domain", nil, "Keep only users with email in given domain (repeatable)")
+	cmd.Flags().StringSliceVar(&flagFilters.ExcludeEmailDomains, "exclude-email-domain", nil, "Filter out users with email in given domain (repeatable)")
+	cmd.Flags().StringSliceVar(&flagFilters.UserGroups, "user-group", nil, "Keep only members of given user group ID or handle (repeatable)")
+	return cmd
+}
+
+// getFilters returns filters from config extended with ones given as flags
+func getFilters(c *config.Config) config.DownloadingUsers {
+	f := c.Slack.DownloadingUsers
+	f.ExcludeGuests = f.ExcludeGuests || flagFilters.ExcludeGuests
+	f.RequireCustomFields = f.RequireCustomFields || flagFilters.RequireCustomFields
+	f.IncludeEmailDomains = append(slices.Clone(f.IncludeEmailDomains), flagFilters.IncludeEmailDomains...)
+	f.ExcludeEmailDomains = append(slices.Clone(f.ExcludeEmailDomains), flagFilters.ExcludeEmailDomains...)
+	f.UserGroups = append(slices.Clone(f.UserGroups), flagFilters.UserGroups...)
+	return f
+}
+
+// getFilteredOutReason returns why user is filtered out or empty string when user should be kept
+func getFilteredOutReason(u slack.User, f config.DownloadingUsers, groupMembers map[string]bool) string {
+	switch {
+	case u.IsBot:
+		return "bot"
+	case u.Deleted:
+		return "deleted"
+	case f.ExcludeGuests && (u.IsRestricted || u.IsUltraRestricted):
+		return "guest"
+	case len(f.IncludeEmailDomains) > 0 && !emailInDomains(u.Email, f.IncludeEmailDomains):
+		return "email_domain"
+	case emailInDomains(u.Email, f.ExcludeEmailDomains):
+		return "email_domain"
+	case groupMembers != nil && !groupMembers[u.ID]:
+		return "user_group"
+	}
+	return ""
+}
+
+func emailInDomains(email string, domains []string) bool {
+	_, domain, found := strings.Cut(strings.ToLower(email), "@")
+	if !found {
+		return false
+	}
+	for _, d := range domains {
+		if domain == strings.ToLower(strings.TrimPrefix(d, "@")) {
+			return true
+		}
+	}
+	return false
 }
 
+// SlackUser has the same fields as config.Person
 type SlackUser struct {
-	Name              string `yaml:"name"`
-	SlackMemberID     string `yaml:"slack_member_id"`
-	BirthDate         string `yaml:"birth_date"`
-	JoinDate          string `yaml:"join_date"`
-	LeadSlackMemberID string `yaml:"lead_slack_member_id"`
+	SlackMemberID     string `yaml:"slack_member_id" json:"slack_member_id"`
+	DisplayName       string `yaml:"display_name" json:"display_name"`
+	RealName          string `yaml:"real_name" json:"real_name"`
+	Email             string `yaml:"email" json:"email"`
+	BirthDate         string `yaml:"birth_date" json:"birth_date"`
+	JoinDate          string `yaml:"join_date" json:"join_date"`
+	LeadSlackMemberID string `yaml:"lead_slack_member_id" json:"lead_slack_member_id"`
+	LeaveDate         string `yaml:"leave_date,omitempty" json:"leave_date,omitempty"`
+}
+
+// SlackUsersFile has the same layout as `people` section of config
+type SlackUsersFile struct {
+	People []SlackUser `yaml:"people" json:"people"`
 }
 
-func downloadUserFromSlack() {
-	c := config.GetConfig()
+func writeSlackUsers(w io.Writer, format string, users []SlackUser) error {
+	switch format {
+	case "yaml":
+		e := yaml.NewEncoder(w)
+		e.SetIndent(2)
+		if err := e.Encode(SlackUsersFile{People: users}); err != nil {
+			return err
+		}
+		return e.Close()
+	case "json":
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		return e.Encode(SlackUsersFile{People: users})
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"slack_member_id", "display_name", "real_name", "email", "birth_date", "join_date", "lead_slack_member_id", "leave_date"})
+		for _, u := range users {
+			cw.Write([]string{u.SlackMemberID, u.DisplayName, u.RealName, u.Email, u.BirthDate, u.JoinDate, u.LeadSlackMemberID, u.LeaveDate})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("Invalid format %q", format)
+}
+
+type UsersDownloader interface {
+	GetUsers(ctx context.Context, onPage func(listed int)) ([]slack.User, error)
+	GetUserProfileFields(ctx context.Context, slackId string) (map[string]string, error)
+	GetUserGroupMembers(ctx context.Context, userGroup string) ([]string, error)
+}
+
+func downloadUserFromSlack(ctx context.Context, c *config.Config, d UsersDownloader) error {
+	SlackUsers, err := DownloadSlackUsers(ctx, c, d)
+	if len(SlackUsers) == 0 && err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if writeErr := writeSlackUsers(&buf, format, SlackUsers); writeErr != nil {
+		return fmt.Errorf("Error marshalling results into %s: %w", format, writeErr)
+	}
 
-	api := slack.New(c.Slack.BotToken)
+	if output == stdoutOutput {
+		if _, writeErr := os.Stdout.Write(buf.Bytes()); writeErr != nil {
+			return fmt.Errorf("Error writing to stdout: %w", writeErr)
+		}
+	} else if writeErr := os.WriteFile(output, buf.Bytes(), 0o644); writeErr != nil {
+		return fmt.Errorf("Error writing to file %s: %w", output, writeErr)
+	}
 
-	users, err := api.GetUsers()
+	slog.Info(
+		"Users downloaded and persisted",
+		"count", len(SlackUsers),
+		"output", output,
+		"format", format,
+	)
+	return err
+}
 
+// DownloadSlackUsers lists users and downloads their profiles concurrently, users whose profiles
+// failed to download are skipped and returned as joined error
+func DownloadSlackUsers(ctx context.Context, c *config.Config, d UsersDownloader) ([]SlackUser, error) {
+	users, err := d.GetUsers(ctx, func(listed int) {
+		slog.Info("Listing users", "listed", listed)
+	})
 	if err != nil {
-		log.Fatal("Error downloading users from Slack", err)
+		return nil, err
 	}
 
-	var SlackUsers []SlackUser
+	f := getFilters(c)
+
+	var groupMembers map[string]bool
+	if len(f.UserGroups) > 0 {
+		groupMembers = map[string]bool{}
+		for _, g := range f.UserGroups {
+			members, err := d.GetUserGroupMembers(ctx, g)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range members {
+				groupMembers[id] = true
+			}
+		}
+	}
 
-	var local_limit int = 0
+	filteredOut := map[string]int{}
+	left := getLeftSlackMemberIDs(c.People)
 
+	var selected []slack.User
 	for _, u := range users {
-		if u.IsBot || u.Deleted {
+		if reason := getFilteredOutReason(u, f, groupMembers); reason != "" {
+			filteredOut[reason]++
 			continue
-		} else {
-			local_limit++
 		}
-
-		if local_limit >= limit {
-			break
+		if left[u.ID] {
+			filteredOut["left"]++
+			continue
 		}
+		selected = append(selected, u)
+	}
 
-		userProfile, err := api.GetUserProfile(
-			&slack.GetUserProfileParameters{UserID: u.ID, IncludeLabels: false},
-		)
+	slog.Info("Downloading user profiles", "count", len(selected), "concurrency", concurrency)
 
-		if err != nil {
-			log.Fatal("Error downloading user profile from Slack", err)
+	// Profiles are downloaded in batches of users still missing to reach the limit, as some of them
+	// may be filtered out by custom fields
+	var (
+		SlackUsers []SlackUser
+		errs       []error
+		downloaded int
+	)
+	for len(selected) > 0 && (limit <= 0 || len(SlackUsers) < limit) {
+		batch := selected
+		if limit > 0 {
+			batch = selected[:min(limit-len(SlackUsers), len(selected))]
 		}
+		selected = selected[len(batch):]
 
-		userProfileMap := userProfile.Fields.ToMap()
+		results, batchErrs := downloadProfiles(ctx, c, d, batch, &downloaded, len(batch)+len(selected)+downloaded)
+		errs = append(errs, batchErrs...)
 
-		p := SlackUser{
-			Name:          u.Profile.DisplayName,
-			SlackMemberID: u.ID,
-			BirthDate:     userProfileMap[c.Slack.DownloadingUsers.BirthdayCustomFieldName].Value,
-			JoinDate:      userProfileMap[c.Slack.DownloadingUsers.JoinDateCustomFieldName].Value,
+		for _, u := range results {
+			if u == nil {
+				continue
+			}
+			if reason := getFilteredOutByFieldsReason(*u, f); reason != "" {
+				filteredOut[reason]++
+				continue
+			}
+			SlackUsers = append(SlackUsers, *u)
 		}
-
-		SlackUsers = append(SlackUsers, p)
 	}
 
-	bytes, err := yaml.Marshal(SlackUsers)
-	if err != nil {
-		log.Fatal("Error marshalling results into yaml", err)
+	for reason, count := range filteredOut {
+		slog.Info("Users filtered out", "reason", reason, "count", count)
 	}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		log.Fatal("Error creating file", err)
+	if len(errs) > 0 {
+		slog.Error("Some user profiles failed to download", "failed", len(errs), "total", downloaded)
+		return SlackUsers, fmt.Errorf(
+			"%d of %d user profiles failed to download: %w",
+			len(errs),
+			downloaded,
+			errors.Join(errs...),
+		)
 	}
-	defer file.Close()
+	return SlackUsers, nil
+}
 
-	_, err = file.Write(bytes)
-	if err != nil {
-		log.Fatal("Error writing to file", err)
+// getFilteredOutByFieldsReason returns why user is filtered out based on profile custom fields or empty
+// string when user should be kept
+func getFilteredOutByFieldsReason(u SlackUser, f config.DownloadingUsers) string {
+	if f.RequireCustomFields && (u.BirthDate == "" || u.JoinDate == "") {
+		return "missing_custom_fields"
+	}
+	if leaveDate, err := time.Parse(time.DateOnly, u.LeaveDate); err == nil && (config.Person{LeaveDate: leaveDate}).HasLeft(getToday()) {
+		return "left"
 	}
+	return ""
+}
 
-	log.Println(
-		fmt.Sprintf(
-			"%d users downloaded and persisted to file %s",
-			len(SlackUsers),
-			filename,
-		),
+// downloadProfiles downloads profiles of users concurrently, results keep order of users with nil for
+// failed downloads, downloaded counts profiles downloaded so far out of total
+func downloadProfiles(
+	ctx context.Context,
+	c *config.Config,
+	d UsersDownloader,
+	users []slack.User,
+	downloaded *int,
+	total int,
+) ([]*SlackUser, []error) {
+	var (
+		results = make([]*SlackUser, len(users))
+		errs    []error
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		indexes = make(chan int)
 	)
+
+	for w := 0; w < max(concurrency, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				u := users[i]
+				fields, err := d.GetUserProfileFields(ctx, u.ID)
+
+				mu.Lock()
+				*downloaded++
+				if err != nil {
+					slog.Warn("Skipping user", "person_id", u.ID, "error", err)
+					errs = append(errs, err)
+				} else {
+					results[i] = &SlackUser{
+						SlackMemberID: u.ID,
+						DisplayName:   u.DisplayName,
+						RealName:      u.RealName,
+						Email:         u.Email,
+						BirthDate:     fields[c.Slack.DownloadingUsers.BirthdayCustomFieldName],
+						JoinDate:      fields[c.Slack.DownloadingUsers.JoinDateCustomFieldName],
+						LeaveDate:     fields[c.Slack.DownloadingUsers.LeaveDateCustomFieldName],
+					}
+				}
+				if *downloaded%progressEvery == 0 || *downloaded == total {
+					slog.Info("Downloading user profiles", "downloaded", *downloaded, "total", total, "failed", len(errs))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range users {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results, errs
 }
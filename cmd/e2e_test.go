@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nomysz/celebrations/slack/fakeslack"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+const e2eConfig = `
+slack:
+  anniversary_channel_reminder:
+    enabled: true
+    channel_name: celebrations
+    message_template: "Happy anniversary <@%s>! %s in company!"
+  birthdays_channel_reminder:
+    enabled: true
+    channel_name: leads
+    message_template: "<@%s> is having birthday today!"
+  birthdays_personal_reminder:
+    enabled: true
+    time: "3pm today"
+    message_template: "<@%s> is having birthday today!"
+  birthdays_direct_message_reminder:
+    enabled: true
+    message_template: "<@%s> is having birthday today!"
+    pre_reminder_days_before: 7
+    pre_remidner_message_template: "<@%s> is having birthday in %d days!"
+    always_notify_slack_ids: [ID00]
+  monthly_report:
+    enabled: true
+    channel_name: leads
+    message_template: "Birthdays:\n%s\nAnniversaries:\n%s"
+  downloading_users:
+    birthday_custom_field_name: XfBirth
+    join_date_custom_field_name: XfJoin
+    leave_date_custom_field_name: XfLeave
+  retry:
+    max_attempts: 3
+    initial_backoff: 1ms
+
+people:
+  - slack_member_id: ID01
+    birth_date: 1990-06-01
+    join_date: 2014-06-01
+    lead_slack_member_id: ID03
+  - slack_member_id: ID02
+    birth_date: 1985-06-08
+    join_date: 2010-01-02
+    lead_slack_member_id: ID03
+`
+
+// runCLI executes app with given args in a directory containing config pointing to fake Slack
+func runCLI(t *testing.T, fake *fakeslack.Server, args ...string) error {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.yml"), []byte(e2eConfig), 0o600))
+
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	defaultLogger := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(defaultLogger) })
+
+	t.Setenv("SLACK_BOT_TOKEN", "e2e-bot-token")
+	t.Setenv("SLACK_USER_TOKEN", "e2e-user-token")
+	t.Setenv("SLACK_API_URL", fake.APIURL())
+
+	// Fresh command so that flags set by previous tests don't leak
+	rootCmd := newRootCmd()
+	rootCmd.SetArgs(append(args, "--log-level", "error"))
+	return rootCmd.Execute()
+}
+
+func TestE2ESendReminders(t *testing.T) {
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 9, 30, 0, 0, time.UTC)
+	}
+
+	fake := fakeslack.New()
+	defer fake.Close()
+
+	assert.NoError(t, runCLI(t, fake, "send-reminders"))
+
+	assert.Contains(t, fake.Messages(), fakeslack.Message{
+		Token:   "e2e-bot-token",
+		Channel: "celebrations",
+		Text:    "Happy anniversary <@ID01>! 2 years in company!",
+	})
+	assert.Contains(t, fake.Messages(), fakeslack.Message{
+		Token:   "e2e-bot-token",
+		Channel: "leads",
+		Text:    "<@ID01> is having birthday today!",
+	})
+	assert.Contains(t, fake.Messages(), fakeslack.Message{
+		Token:   "e2e-bot-token",
+		Channel: "DID03",
+		Text:    "<@ID01> is having birthday today!",
+	})
+	assert.Contains(t, fake.Messages(), fakeslack.Message{
+		Token:   "e2e-bot-token",
+		Channel: "DID03",
+		Text:    "<@ID02> is having birthday in 7 days!",
+	})
+	assert.Contains(t, fake.Messages(), fakeslack.Message{
+		Token:   "e2e-bot-token",
+		Channel: "leads",
+		Text:    "Birthdays:\n1 June, <@ID01> 26 years old\n8 June, <@ID02> 31 years old\n\nAnniversaries:\n1 June, <@ID01> 2 years in company\n",
+	})
+	assert.Equal(t, []fakeslack.Reminder{{
+		Token: "e2e-user-token",
+		User:  "ID03",
+		Text:  "<@ID01> is having birthday today!",
+		Time:  "3pm today",
+	}}, fake.Reminders())
+}
+
+func TestE2ESendRemindersFailure(t *testing.T) {
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 9, 30, 0, 0, time.UTC)
+	}
+
+	fake := fakeslack.New()
+	defer fake.Close()
+	fake.SetError("reminders.add", "not_allowed_token_type")
+
+	assert.ErrorContains(t, runCLI(t, fake, "send-reminders"), "not_allowed_token_type")
+	assert.NotEmpty(t, fake.Messages(), "Failure of one handler stopped others")
+}
+
+func TestE2EPreflight(t *testing.T) {
+	fake := fakeslack.New()
+	defer fake.Close()
+	fake.SetScopes("chat:write", "channels:read", "groups:read", "im:write", "reminders:write")
+	fake.AddChannel(fakeslack.Channel{ID: "C01", Name: "celebrations", IsMember: true})
+
+	assert.ErrorContains(t, runCLI(t, fake, "send-reminders", "--preflight"), "Channel leads used by")
+	assert.Empty(t, fake.Messages(), "Messages sent despite failed preflight")
+
+	fake.AddChannel(fakeslack.Channel{ID: "C02", Name: "leads", IsPrivate: true, IsMember: true})
+	assert.NoError(t, runCLI(t, fake, "doctor"))
+}
+
+func TestE2EDownloadUsers(t *testing.T) {
+	fake := fakeslack.New()
+	defer fake.Close()
+	fake.AddUser(fakeslack.User{ID: "ID01", DisplayName: "John", RealName: "John Smith", Email: "john@company.com", Fields: map[string]string{"XfBirth": "1990-06-01", "XfJoin": "2014-06-01"}})
+	fake.AddUser(fakeslack.User{ID: "BOT01", IsBot: true})
+	fake.AddUser(fakeslack.User{ID: "ID02", DisplayName: "Jane", Deleted: true})
+	fake.AddUser(fakeslack.User{ID: "ID03", DisplayName: "Mary", Fields: map[string]string{"XfBirth": "1985-06-08"}})
+
+	assert.NoError(t, runCLI(t, fake, "download-users"))
+
+	bytes, err := os.ReadFile("people.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, `people:
+  - slack_member_id: ID01
+    display_name: John
+    real_name: John Smith
+    email: john@company.com
+    birth_date: "1990-06-01"
+    join_date: "2014-06-01"
+    lead_slack_member_id: ""
+  - slack_member_id: ID03
+    display_name: Mary
+    real_name: ""
+    email: ""
+    birth_date: "1985-06-08"
+    join_date: ""
+    lead_slack_member_id: ""
+`, string(bytes))
+
+	assert.NoError(t, runCLI(t, fake, "download-users", "--format", "csv", "--output", "people.csv"))
+
+	bytes, err = os.ReadFile("people.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, `slack_member_id,display_name,real_name,email,birth_date,join_date,lead_slack_member_id,leave_date
+ID01,John,John Smith,john@company.com,1990-06-01,2014-06-01,,
+ID03,Mary,,,1985-06-08,,,
+`, string(bytes))
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	os.Stdout = w
+	err = runCLI(t, fake, "download-users", "-f", "json", "-o", "-")
+	w.Close()
+	os.Stdout = stdout
+	assert.NoError(t, err)
+
+	bytes, err = io.ReadAll(r)
+	assert.NoError(t, err)
+	var file SlackUsersFile
+	assert.NoError(t, json.Unmarshal(bytes, &file))
+	assert.Equal(t, []SlackUser{
+		{SlackMemberID: "ID01", DisplayName: "John", RealName: "John Smith", Email: "john@company.com", BirthDate: "1990-06-01", JoinDate: "2014-06-01"},
+		{SlackMemberID: "ID03", DisplayName: "Mary", BirthDate: "1985-06-08"},
+	}, file.People)
+
+	assert.ErrorContains(t, runCLI(t, fake, "download-users", "--format", "xml"), "Invalid format")
+}
+
+func TestE2EDownloadUsersInBulk(t *testing.T) {
+	fake := fakeslack.New()
+	defer fake.Close()
+
+	for i := 0; i < 450; i++ {
+		fake.AddUser(fakeslack.User{
+			ID:     fmt.Sprintf("ID%03d", i),
+			Fields: map[string]string{"XfBirth": "1990-06-01", "XfJoin": "2014-06-01"},
+		})
+	}
+	fake.AddUser(fakeslack.User{ID: "ID999", ProfileError: "user_not_found"})
+	fake.SetRateLimit("users.list", 1)
+	fake.SetRateLimit("users.profile.get", 2)
+
+	err := runCLI(t, fake, "download-users", "--limit", "0", "--concurrency", "8")
+	assert.ErrorContains(t, err, "1 of 451 user profiles failed to download")
+	assert.ErrorContains(t, err, "ID999")
+
+	assert.Equal(t, 4, fake.Calls("users.list"), "Users not paginated or rate limit not retried")
+
+	bytes, err := os.ReadFile("people.yml")
+	assert.NoError(t, err)
+
+	var file SlackUsersFile
+	assert.NoError(t, yaml.Unmarshal(bytes, &file))
+	people := file.People
+	if assert.Len(t, people, 450) {
+		assert.Equal(t, "ID000", people[0].SlackMemberID, "Order of users not preserved")
+		assert.Equal(t, "ID449", people[449].SlackMemberID, "Order of users not preserved")
+	}
+}
+
+func TestE2EDownloadUsersFilters(t *testing.T) {
+	fake := fakeslack.New()
+	defer fake.Close()
+
+	dates := map[string]string{"XfBirth": "1990-06-01", "XfJoin": "2014-06-01"}
+	fake.AddUser(fakeslack.User{ID: "ID01", Email: "john@company.com", Fields: dates})
+	fake.AddUser(fakeslack.User{ID: "ID02", Email: "jane@company.com", IsRestricted: true, Fields: dates})
+	fake.AddUser(fakeslack.User{ID: "ID03", Email: "mary@company.com", IsUltraRestricted: true, Fields: dates})
+	fake.AddUser(fakeslack.User{ID: "ID04", Email: "bob@contractors.com", Fields: dates})
+	fake.AddUser(fakeslack.User{ID: "ID05", Email: "ann@company.com", Fields: map[string]string{"XfBirth": "1990-06-01"}})
+	fake.AddUser(fakeslack.User{ID: "ID06", Email: "tom@company.com", Fields: dates})
+	fake.AddUserGroup(fakeslack.UserGroup{ID: "S01", Handle: "staff", Members: []string{"ID01", "ID02", "ID03", "ID04", "ID05"}})
+
+	assert.NoError(t, runCLI(t, fake,
+		"download-users",
+		"--exclude-guests",
+		"--require-custom-fields",
+		"--exclude-email-domain", "contractors.com",
+		"--user-group", "@staff",
+	))
+
+	bytes, err := os.ReadFile("people.yml")
+	assert.NoError(t, err)
+
+	var file SlackUsersFile
+	assert.NoError(t, yaml.Unmarshal(bytes, &file))
+	people := file.People
+	if assert.Len(t, people, 1) {
+		assert.Equal(t, "ID01", people[0].SlackMemberID)
+	}
+
+	assert.NoError(t, runCLI(t, fake, "download-users", "--include-email-domain", "contractors.com"))
+
+	bytes, err = os.ReadFile("people.yml")
+	assert.NoError(t, err)
+	assert.NoError(t, yaml.Unmarshal(bytes, &file))
+	people = file.People
+	if assert.Len(t, people, 1, "Filters from previous run or include domain filter not applied") {
+		assert.Equal(t, "ID04", people[0].SlackMemberID)
+	}
+}
+
+func TestE2EDownloadUsersLimitAfterFilters(t *testing.T) {
+	fake := fakeslack.New()
+	defer fake.Close()
+
+	dates := map[string]string{"XfBirth": "1990-06-01", "XfJoin": "2014-06-01"}
+	fake.AddUser(fakeslack.User{ID: "ID01", Fields: map[string]string{"XfBirth": "1990-06-01"}})
+	fake.AddUser(fakeslack.User{ID: "ID02", Fields: dates})
+	fake.AddUser(fakeslack.User{ID: "ID03", Fields: map[string]string{"XfJoin": "2014-06-01"}})
+	fake.AddUser(fakeslack.User{ID: "ID04", Fields: dates})
+	fake.AddUser(fakeslack.User{ID: "ID05", Fields: dates})
+
+	assert.NoError(t, runCLI(t, fake, "download-users", "--require-custom-fields", "--limit", "2"))
+
+	bytes, err := os.ReadFile("people.yml")
+	assert.NoError(t, err)
+
+	var file SlackUsersFile
+	assert.NoError(t, yaml.Unmarshal(bytes, &file))
+	if assert.Len(t, file.People, 2, "Limit applied before filters") {
+		assert.Equal(t, "ID02", file.People[0].SlackMemberID)
+		assert.Equal(t, "ID04", file.People[1].SlackMemberID)
+	}
+	assert.Equal(t, 4, fake.Calls("users.profile.get"), "Profiles beyond limit downloaded")
+}
+
+func TestE2EDownloadUsersLeaveDate(t *testing.T) {
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 9, 30, 0, 0, time.UTC)
+	}
+
+	fake := fakeslack.New()
+	defer fake.Close()
+
+	fake.AddUser(fakeslack.User{ID: "ID01", Fields: map[string]string{"XfBirth": "1990-06-01", "XfJoin": "2014-06-01", "XfLeave": "2016-05-31"}})
+	fake.AddUser(fakeslack.User{ID: "ID02", Fields: map[string]string{"XfBirth": "1985-06-08", "XfJoin": "2020-01-02", "XfLeave": "2016-06-01"}})
+	fake.AddUser(fakeslack.User{ID: "ID03", Fields: map[string]string{"XfBirth": "1985-06-08", "XfJoin": "2020-01-02"}})
+
+	assert.NoError(t, runCLI(t, fake, "download-users"))
+
+	bytes, err := os.ReadFile("people.yml")
+	assert.NoError(t, err)
+
+	var file SlackUsersFile
+	assert.NoError(t, yaml.Unmarshal(bytes, &file))
+	assert.Equal(t, []SlackUser{
+		{SlackMemberID: "ID02", BirthDate: "1985-06-08", JoinDate: "2020-01-02", LeaveDate: "2016-06-01"},
+		{SlackMemberID: "ID03", BirthDate: "1985-06-08", JoinDate: "2020-01-02"},
+	}, file.People)
+
+	assert.NoError(t, runCLI(t, fake, "download-users", "--limit", "2"))
+
+	bytes, err = os.ReadFile("people.yml")
+	assert.NoError(t, err)
+	assert.NoError(t, yaml.Unmarshal(bytes, &file))
+	assert.Len(t, file.People, 2, "Limit applied before filtering out users who have left")
+}
@@ -1,69 +1,203 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"slices"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/nomysz/celebrations/config"
 	"github.com/nomysz/celebrations/slack"
 )
 
-func SlackMonthlyReportHandler(e MonthlyReportEvent, c *config.Config, s slack.ChannelMessenger) {
+func SlackMonthlyReportHandler(e ReportEvent, c *config.Config, s slack.ChannelMessenger) error {
+	r := c.Slack.MonthlyReport
+	if err := sendReport(e, r.ChannelName, r.MessageTemplate, r.BirthdayLineTemplate, r.AnniversaryLineTemplate, s); err != nil {
+		return fmt.Errorf("Error when posting monthly report reminder: %w", err)
+	}
+	slog.Info(
+		"Sent monthly report to channel",
+		"event_type", e.GetType().String(),
+		"handler", MonthlyReportHandler,
+		"channel", r.ChannelName,
+	)
+	return nil
+}
+
+func SlackWeeklyDigestHandler(e ReportEvent, c *config.Config, s slack.ChannelMessenger) error {
+	d := c.Slack.WeeklyDigest
+	if err := sendReport(e, d.ChannelName, d.MessageTemplate, d.BirthdayLineTemplate, d.AnniversaryLineTemplate, s); err != nil {
+		return fmt.Errorf("Error when posting weekly digest: %w", err)
+	}
+	slog.Info(
+		"Sent weekly digest to channel",
+		"event_type", e.GetType().String(),
+		"handler", WeeklyDigestHandler,
+		"channel", d.ChannelName,
+	)
+	return nil
+}
+
+func SlackLeadDigestHandler(e ReportEvent, c *config.Config, s slack.DirectMessenger) error {
+	if len(e.Birthdays) == 0 && len(e.Anniversaries) == 0 {
+		return skip("no celebrations of direct reports of %s", e.LeadSlackMemberID)
+	}
+	d := c.Slack.LeadDigest
+	msg, err := getReportMessage(e, d.MessageTemplate, d.BirthdayLineTemplate, d.AnniversaryLineTemplate)
+	if err != nil {
+		return fmt.Errorf("Error when sending lead digest: %w", err)
+	}
+	if err := s.SendDirectMessage(e.LeadSlackMemberID, msg); err != nil {
+		return fmt.Errorf("Error when sending lead digest: %w", err)
+	}
+	slog.Info(
+		"Sent lead digest Slack DM to lead",
+		"event_type", e.GetType().String(),
+		"handler", LeadDigestHandler,
+		"lead_id", e.LeadSlackMemberID,
+	)
+	return nil
+}
+
+// sendReport posts birthdays and anniversaries of report event to channel
+func sendReport(
+	e ReportEvent,
+	channel string,
+	msgTemplate string,
+	birthdayLineTemplate string,
+	anniversaryLineTemplate string,
+	s slack.ChannelMessenger,
+) error {
+	msg, err := getReportMessage(e, msgTemplate, birthdayLineTemplate, anniversaryLineTemplate)
+	if err != nil {
+		return err
+	}
+	return s.SendChannelMessage(channel, msg)
+}
+
+// getReportMessage returns birthdays and anniversaries of report event sorted by date
+func getReportMessage(
+	e ReportEvent,
+	msgTemplate string,
+	birthdayLineTemplate string,
+	anniversaryLineTemplate string,
+) (string, error) {
 	var textBirthdays, textAnniversaries string
 
-	sort.Slice(e.Birthdays, func(i, j int) bool {
-		if e.Birthdays[i].BirthDate.Month() == e.Birthdays[j].BirthDate.Month() {
-			return e.Birthdays[i].BirthDate.Day() < e.Birthdays[j].BirthDate.Day()
-		}
-		return e.Birthdays[i].BirthDate.Month() < e.Birthdays[j].BirthDate.Month()
+	sort.SliceStable(e.Birthdays, func(i, j int) bool {
+		return getNextOccurrence(e.Birthdays[i].BirthDate, e.From).Before(getNextOccurrence(e.Birthdays[j].BirthDate, e.From))
 	})
 
-	sort.Slice(e.Anniversaries, func(i, j int) bool {
-		if e.Anniversaries[i].JoinDate.Month() == e.Anniversaries[j].JoinDate.Month() {
-			return e.Anniversaries[i].JoinDate.Day() < e.Anniversaries[j].JoinDate.Day()
-		}
-		return e.Anniversaries[i].JoinDate.Month() < e.Anniversaries[j].JoinDate.Month()
+	sort.SliceStable(e.Anniversaries, func(i, j int) bool {
+		return getNextOccurrence(e.Anniversaries[i].JoinDate, e.From).Before(getNextOccurrence(e.Anniversaries[j].JoinDate, e.From))
 	})
 
 	for _, p := range e.Birthdays {
-		textBirthdays += fmt.Sprintf(
-			"%s, <@%s> %d years old\n",
-			p.BirthDate.Format("2 January"),
-			p.SlackMemberID,
-			getYearsPassedToCurrentYear(p.BirthDate),
-		)
+		line, err := getBirthdayLine(p, e.From, birthdayLineTemplate)
+		if err != nil {
+			return "", err
+		}
+		textBirthdays += line
 	}
 
 	for _, p := range e.Anniversaries {
-		textAnniversaries += fmt.Sprintf(
-			"%s, <@%s> %s in company\n",
-			p.JoinDate.Format("2 January"),
-			p.SlackMemberID,
-			getYearsText(p.JoinDate),
-		)
+		line, err := getAnniversaryLine(p, e.From, anniversaryLineTemplate)
+		if err != nil {
+			return "", err
+		}
+		textAnniversaries += line
 	}
 
-	monthlyReport := fmt.Sprintf(
-		c.Slack.MonthlyReport.MessageTemplate,
-		textBirthdays,
-		textAnniversaries,
-	)
-	if err := s.SendChannelMessage(
-		c.Slack.MonthlyReport.ChannelName,
-		monthlyReport,
-	); err != nil {
-		log.Println("Error when posting monthly report reminder:", err)
-		return
+	data := TemplateData{CustomEvents: map[string]string{}}
+	for _, ce := range e.CompanyEvents {
+		data.CompanyEvents += getCompanyEventLine(ce, e.From)
 	}
-	log.Println("Sent monthly report to channel", c.Slack.MonthlyReport.ChannelName)
+	for _, r := range e.CustomEvents {
+		sort.SliceStable(r.People, func(i, j int) bool {
+			return getNextOccurrence(r.People[i].Dates[r.Event.DateField], e.From).Before(
+				getNextOccurrence(r.People[j].Dates[r.Event.DateField], e.From),
+			)
+		})
+		var text string
+		for _, p := range r.People {
+			line, err := getCustomEventLine(p, r.Event, e.From)
+			if err != nil {
+				return "", err
+			}
+			text += line
+		}
+		data.CustomEvents[r.Event.Name] = text
+	}
+
+	return renderTemplate(msgTemplate, data, textBirthdays, textAnniversaries)
+}
+
+// getCompanyEventLine returns report line of company event
+func getCompanyEventLine(ce config.CompanyEvent, from time.Time) string {
+	date := getCompanyEventDate(ce, from)
+	if years := date.Year() - ce.Date.Year(); ce.Yearly && years > 0 {
+		return fmt.Sprintf("%s, %s (%s)\n", date.Format("2 January"), ce.Name, formatYears(years))
+	}
+	return fmt.Sprintf("%s, %s\n", date.Format("2 January"), ce.Name)
+}
+
+// getCustomEventLine returns report line rendered with custom event line template if configured
+func getCustomEventLine(p config.Person, ce config.CustomEvent, from time.Time) (string, error) {
+	occurrence := getNextOccurrence(p.Dates[ce.DateField], from)
+	data := TemplateData{
+		Person: p,
+		Date:   occurrence.Format("2 January"),
+		Years:  occurrence.Year() - p.Dates[ce.DateField].Year(),
+	}
+	if ce.ReportLineTemplate != "" {
+		line, err := renderTemplate(ce.ReportLineTemplate, data)
+		return line + "\n", err
+	}
+	return fmt.Sprintf("%s, %s\n", data.Date, p.Mention()), nil
+}
+
+// getBirthdayLine returns report line rendered with line template if configured
+func getBirthdayLine(p config.Person, from time.Time, lineTemplate string) (string, error) {
+	occurrence := getNextOccurrence(p.BirthDate, from)
+	data := TemplateData{Person: p, Date: occurrence.Format("2 January")}
+	if !p.Privacy.HideAge {
+		data.Years = occurrence.Year() - p.BirthDate.Year()
+	}
+	if lineTemplate != "" {
+		line, err := renderTemplate(lineTemplate, data)
+		return line + "\n", err
+	}
+	if p.Privacy.HideAge {
+		return fmt.Sprintf("%s, %s\n", data.Date, p.Mention()), nil
+	}
+	return fmt.Sprintf("%s, %s %d years old\n", data.Date, p.Mention(), data.Years), nil
+}
+
+// getAnniversaryLine returns report line rendered with line template if configured
+func getAnniversaryLine(p config.Person, from time.Time, lineTemplate string) (string, error) {
+	occurrence := getNextOccurrence(p.JoinDate, from)
+	data := TemplateData{
+		Person: p,
+		Date:   occurrence.Format("2 January"),
+		Years:  occurrence.Year() - p.JoinDate.Year(),
+	}
+	if lineTemplate != "" {
+		line, err := renderTemplate(lineTemplate, data)
+		return line + "\n", err
+	}
+	return fmt.Sprintf("%s, %s %s in company\n", data.Date, p.Mention(), formatYears(data.Years)), nil
 }
 
 func getYearsText(date time.Time) string {
-	yearsInCompany := getYearsPassedToCurrentYear(date)
-	if yearsInCompany > 1 {
-		return fmt.Sprintf("%d years", yearsInCompany)
+	return formatYears(getYearsPassedToCurrentYear(date))
+}
+
+func formatYears(years int) string {
+	if years > 1 {
+		return fmt.Sprintf("%d years", years)
 	}
 	return "1 year"
 }
@@ -72,76 +206,575 @@ func getYearsPassedToCurrentYear(birthday time.Time) int {
 	return GetNow().Year() - birthday.Year()
 }
 
-func SlackAnniversaryChannelHandler(e PersonalEvent, c *config.Config, s slack.ChannelMessenger) {
-	anniversaryWishes := fmt.Sprintf(
+func SlackAnniversaryChannelHandler(e PersonalEvent, c *config.Config, s slack.ChannelMessenger) error {
+	if !e.Person.AllowsPublicPosts() {
+		return skip("privacy preferences of %s", e.Person.SlackMemberID)
+	}
+	anniversaryWishes, err := renderTemplate(
 		c.Slack.AnniversaryChannelReminder.MessageTemplate,
+		TemplateData{Person: e.Person},
 		e.Person.SlackMemberID,
 		getYearsText(e.Person.JoinDate),
 	)
+	if err != nil {
+		return fmt.Errorf("Error when posting anniversary reminder: %w", err)
+	}
 	if err := s.SendChannelMessage(
 		c.Slack.AnniversaryChannelReminder.ChannelName,
 		anniversaryWishes,
 	); err != nil {
-		log.Println("Error when posting anniversary reminder:", err)
-		return
+		return fmt.Errorf("Error when posting anniversary reminder: %w", err)
 	}
-	log.Println("Sent anniversary info to channel for person", e.Person.SlackMemberID)
+	slog.Info(
+		"Sent anniversary info to channel",
+		"event_type", e.GetType().String(),
+		"person_id", e.Person.SlackMemberID,
+		"handler", AnniversaryChannelHandler,
+		"channel", c.Slack.AnniversaryChannelReminder.ChannelName,
+	)
+	return nil
 }
 
-func SlackBirthdayReminderChannelHandler(e PersonalEvent, c *config.Config, s slack.ChannelMessenger) {
+func SlackBirthdayReminderChannelHandler(e PersonalEvent, c *config.Config, s slack.ChannelMessenger) error {
+	if !e.Person.AllowsChannelPosts() {
+		return skip("privacy preferences of %s", e.Person.SlackMemberID)
+	}
+	msg, err := renderTemplate(
+		c.Slack.BirthdaysChannelReminder.MessageTemplate,
+		TemplateData{Person: e.Person},
+		e.Person.SlackMemberID,
+	)
+	if err != nil {
+		return fmt.Errorf("Error when posting birthday reminder: %w", err)
+	}
 	if err := s.SendChannelMessage(
 		c.Slack.BirthdaysChannelReminder.ChannelName,
-		fmt.Sprintf(c.Slack.BirthdaysChannelReminder.MessageTemplate, e.Person.SlackMemberID),
+		msg,
 	); err != nil {
-		log.Println("Error when posting birthday reminder:", err)
-		return
+		return fmt.Errorf("Error when posting birthday reminder: %w", err)
 	}
-	log.Println("Sent birthday reminder to channel", e.Person.SlackMemberID)
+	slog.Info(
+		"Sent birthday reminder to channel",
+		"event_type", e.GetType().String(),
+		"person_id", e.Person.SlackMemberID,
+		"handler", BirthdayReminderChannelHandler,
+		"channel", c.Slack.BirthdaysChannelReminder.ChannelName,
+	)
+	return nil
+}
+
+func SlackAnniversaryGroupChannelHandler(e GroupEvent, c *config.Config, s slack.ChannelMessenger) error {
+	r := c.Slack.AnniversaryChannelReminder
+	var lines []string
+	for _, p := range e.People {
+		line, err := renderTemplate(
+			r.GroupedLineTemplate,
+			TemplateData{Person: p, Years: getYearsPassedToCurrentYear(p.JoinDate)},
+			p.SlackMemberID,
+			getYearsText(p.JoinDate),
+		)
+		if err != nil {
+			return fmt.Errorf("Error when posting grouped anniversary reminder: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	joined := strings.Join(lines, "\n")
+	msg, err := renderTemplate(r.GroupedMessageTemplate, TemplateData{Lines: joined}, joined)
+	if err != nil {
+		return fmt.Errorf("Error when posting grouped anniversary reminder: %w", err)
+	}
+	if err := s.SendChannelMessage(r.ChannelName, msg); err != nil {
+		return fmt.Errorf("Error when posting grouped anniversary reminder: %w", err)
+	}
+	slog.Info(
+		"Sent grouped anniversary info to channel",
+		"event_type", e.GetType().String(),
+		"person_ids", strings.Join(e.getSlackMemberIDs(), ","),
+		"handler", AnniversaryChannelHandler,
+		"channel", r.ChannelName,
+	)
+	return nil
+}
+
+func SlackBirthdayGroupChannelHandler(e GroupEvent, c *config.Config, s slack.ChannelMessenger) error {
+	r := c.Slack.BirthdaysChannelReminder
+	var lines []string
+	for _, p := range e.People {
+		line, err := renderTemplate(r.GroupedLineTemplate, TemplateData{Person: p}, p.SlackMemberID)
+		if err != nil {
+			return fmt.Errorf("Error when posting grouped birthday reminder: %w", err)
+		}
+		lines = append(lines, line)
+	}
+	joined := strings.Join(lines, "\n")
+	msg, err := renderTemplate(r.GroupedMessageTemplate, TemplateData{Lines: joined}, joined)
+	if err != nil {
+		return fmt.Errorf("Error when posting grouped birthday reminder: %w", err)
+	}
+	if err := s.SendChannelMessage(r.ChannelName, msg); err != nil {
+		return fmt.Errorf("Error when posting grouped birthday reminder: %w", err)
+	}
+	slog.Info(
+		"Sent grouped birthday reminder to channel",
+		"event_type", e.GetType().String(),
+		"person_ids", strings.Join(e.getSlackMemberIDs(), ","),
+		"handler", BirthdayReminderChannelHandler,
+		"channel", r.ChannelName,
+	)
+	return nil
 }
 
-func SlackBirthdayReminderDirectMessageHandler(e PersonalEvent, c *config.Config, s slack.DirectMessenger) {
-	var msg string
+func SlackBirthdayReminderDirectMessageHandler(e PersonalEvent, c *config.Config, s slack.DirectMessenger) error {
+	if !e.Person.IsCelebrated() {
+		return skip("privacy preferences of %s", e.Person.SlackMemberID)
+	}
+	var (
+		msg string
+		err error
+	)
 	switch e.GetType() {
 	case Birthday:
-		msg = fmt.Sprintf(
+		msg, err = renderTemplate(
 			c.Slack.BirthdaysDirectMessageReminder.MessageTemplate,
+			TemplateData{Person: e.Person},
 			e.Person.SlackMemberID,
 		)
 	case UpcomingBirthday:
-		msg = fmt.Sprintf(
-			c.Slack.BirthdaysDirectMessageReminder.PreRemidnerMessageTemplate,
+		msg, err = renderTemplate(
+			e.PreReminder.MessageTemplate,
+			TemplateData{Person: e.Person, DaysBefore: e.PreReminder.DaysBefore},
 			e.Person.SlackMemberID,
-			c.Slack.BirthdaysDirectMessageReminder.PreReminderDaysBefore,
+			e.PreReminder.DaysBefore,
 		)
 	default:
-		log.Println("Error when sending DM remidner: Invalid EventType:", e.GetType())
-		return
+		return fmt.Errorf("Error when sending DM remidner: Invalid EventType: %d", e.GetType())
+	}
+	if err != nil {
+		return fmt.Errorf("Error when sending DM remidner: %w", err)
 	}
 
-	if err := s.SendDirectMessage(*e.Person.LeadSlackMemberID, msg); err != nil {
-		log.Println("Error when sending DM remidner:", err)
-		return
+	recipients := getDirectMessageRecipients(
+		e.Person,
+		c,
+		e.OrgChart,
+		c.Slack.BirthdaysDirectMessageReminder.NotifyManagementChainDepth,
+		c.Slack.BirthdaysDirectMessageReminder.AlwaysNotifySlackIds,
+	)
+	if len(recipients) == 0 {
+		return skip("no lead of %s", e.Person.SlackMemberID)
 	}
-	for _, slackMemberID := range c.Slack.BirthdaysDirectMessageReminder.AlwaysNotifySlackIds {
+
+	var errs []error
+	for _, slackMemberID := range recipients {
 		if err := s.SendDirectMessage(slackMemberID, msg); err != nil {
-			log.Println("Error when sending DM remidner:", err)
-			return
+			errs = append(errs, fmt.Errorf("Error when sending DM remidner: %w", err))
 		}
 	}
-	log.Println("Sent birthday reminder Slack DM to lead", e.Person.SlackMemberID)
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	slog.Info(
+		"Sent birthday reminder Slack DM to lead",
+		"event_type", e.GetType().String(),
+		"person_id", e.Person.SlackMemberID,
+		"handler", BirthdayReminderDirectMessageHandler,
+		"lead_id", recipients[0],
+		"recipients", len(recipients),
+	)
+	return nil
 }
 
-func SlackBirthdayPersonalReminderHandler(e PersonalEvent, c *config.Config, s slack.PersonalReminderSetter) {
-	if e.Person.LeadSlackMemberID == nil {
-		return
+func SlackAnniversaryReminderDirectMessageHandler(e PersonalEvent, c *config.Config, s slack.DirectMessenger) error {
+	if !e.Person.IsCelebrated() {
+		return skip("privacy preferences of %s", e.Person.SlackMemberID)
+	}
+	var (
+		msg string
+		err error
+	)
+	switch e.GetType() {
+	case Anniversary:
+		msg, err = renderTemplate(
+			c.Slack.AnniversariesDirectMessageReminder.MessageTemplate,
+			TemplateData{Person: e.Person},
+			e.Person.SlackMemberID,
+			getYearsText(e.Person.JoinDate),
+		)
+	case UpcomingAnniversary:
+		years := getNextOccurrence(e.Person.JoinDate, getToday()).Year() - e.Person.JoinDate.Year()
+		msg, err = renderTemplate(
+			e.PreReminder.MessageTemplate,
+			TemplateData{Person: e.Person, Years: years, DaysBefore: e.PreReminder.DaysBefore},
+			e.Person.SlackMemberID,
+			formatYears(years),
+			e.PreReminder.DaysBefore,
+		)
+	default:
+		return fmt.Errorf("Error when sending DM reminder: Invalid EventType: %d", e.GetType())
+	}
+	if err != nil {
+		return fmt.Errorf("Error when sending DM reminder: %w", err)
+	}
+
+	recipients := getDirectMessageRecipients(
+		e.Person,
+		c,
+		e.OrgChart,
+		c.Slack.AnniversariesDirectMessageReminder.NotifyManagementChainDepth,
+		c.Slack.AnniversariesDirectMessageReminder.AlwaysNotifySlackIds,
+	)
+	if len(recipients) == 0 {
+		return skip("no lead of %s", e.Person.SlackMemberID)
+	}
+
+	var errs []error
+	for _, slackMemberID := range recipients {
+		if err := s.SendDirectMessage(slackMemberID, msg); err != nil {
+			errs = append(errs, fmt.Errorf("Error when sending DM reminder: %w", err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	slog.Info(
+		"Sent anniversary reminder Slack DM to lead",
+		"event_type", e.GetType().String(),
+		"person_id", e.Person.SlackMemberID,
+		"handler", AnniversaryDirectMessageHandler,
+		"lead_id", recipients[0],
+		"recipients", len(recipients),
+	)
+	return nil
+}
+
+func SlackCustomEventChannelHandler(e PersonalEvent, c *config.Config, s slack.ChannelMessenger) error {
+	if !e.Person.AllowsPublicPosts() {
+		return skip("privacy preferences of %s", e.Person.SlackMemberID)
+	}
+	date := e.Person.Dates[e.CustomEvent.DateField]
+	msg, err := renderTemplate(
+		e.CustomEvent.MessageTemplate,
+		TemplateData{Person: e.Person, Years: getYearsPassedToCurrentYear(date)},
+		e.Person.SlackMemberID,
+	)
+	if err != nil {
+		return fmt.Errorf("Error when posting %s reminder: %w", e.CustomEvent.Name, err)
+	}
+	if err := s.SendChannelMessage(e.CustomEvent.ChannelName, msg); err != nil {
+		return fmt.Errorf("Error when posting %s reminder: %w", e.CustomEvent.Name, err)
+	}
+	slog.Info(
+		"Sent custom event reminder to channel",
+		"event_type", e.GetType().String(),
+		"custom_event", e.CustomEvent.Name,
+		"person_id", e.Person.SlackMemberID,
+		"handler", CustomEventChannelHandler,
+		"channel", e.CustomEvent.ChannelName,
+	)
+	return nil
+}
+
+func SlackCustomEventDirectMessageHandler(e PersonalEvent, c *config.Config, s slack.DirectMessenger) error {
+	if !e.Person.IsCelebrated() {
+		return skip("privacy preferences of %s", e.Person.SlackMemberID)
+	}
+	date := e.Person.Dates[e.CustomEvent.DateField]
+	years := getNextOccurrence(date, getToday()).Year() - date.Year()
+	msg, err := renderTemplate(
+		e.PreReminder.MessageTemplate,
+		TemplateData{Person: e.Person, Years: years, DaysBefore: e.PreReminder.DaysBefore},
+		e.Person.SlackMemberID,
+		e.PreReminder.DaysBefore,
+	)
+	if err != nil {
+		return fmt.Errorf("Error when sending %s DM reminder: %w", e.CustomEvent.Name, err)
+	}
+
+	lead, _ := getLeadOrFallback(e.Person, c, e.OrgChart)
+	if lead == "" {
+		return skip("no lead of %s", e.Person.SlackMemberID)
+	}
+	if err := s.SendDirectMessage(lead, msg); err != nil {
+		return fmt.Errorf("Error when sending %s DM reminder: %w", e.CustomEvent.Name, err)
+	}
+	slog.Info(
+		"Sent custom event reminder Slack DM to lead",
+		"event_type", e.GetType().String(),
+		"custom_event", e.CustomEvent.Name,
+		"person_id", e.Person.SlackMemberID,
+		"handler", CustomEventDirectMessageHandler,
+		"lead_id", lead,
+	)
+	return nil
+}
+
+func SlackCompanyEventChannelHandler(e CompanyEvent, c *config.Config, s slack.ChannelMessenger) error {
+	msg, err := renderTemplate(
+		e.CompanyEvent.MessageTemplate,
+		TemplateData{
+			EventName: e.CompanyEvent.Name,
+			Date:      getToday().Format("2 January"),
+			Years:     getYearsPassedToCurrentYear(e.CompanyEvent.Date),
+		},
+		e.CompanyEvent.Name,
+	)
+	if err != nil {
+		return fmt.Errorf("Error when posting company event %s: %w", e.CompanyEvent.Name, err)
+	}
+	if err := s.SendChannelMessage(e.CompanyEvent.ChannelName, msg); err != nil {
+		return fmt.Errorf("Error when posting company event %s: %w", e.CompanyEvent.Name, err)
+	}
+	slog.Info(
+		"Sent company event to channel",
+		"event_type", e.GetType().String(),
+		"company_event", e.CompanyEvent.Name,
+		"handler", CompanyEventChannelHandler,
+		"channel", e.CompanyEvent.ChannelName,
+	)
+	return nil
+}
+
+func SlackWelcomeChannelHandler(e PersonalEvent, c *config.Config, s slack.ChannelMessenger) error {
+	if !e.Person.AllowsPublicPosts() {
+		return skip("privacy preferences of %s", e.Person.SlackMemberID)
+	}
+	msg, err := renderTemplate(
+		c.Slack.Onboarding.WelcomeMessageTemplate,
+		TemplateData{Person: e.Person},
+		e.Person.SlackMemberID,
+	)
+	if err != nil {
+		return fmt.Errorf("Error when posting welcome message: %w", err)
+	}
+	if err := s.SendChannelMessage(c.Slack.Onboarding.WelcomeChannelName, msg); err != nil {
+		return fmt.Errorf("Error when posting welcome message: %w", err)
+	}
+	slog.Info(
+		"Sent welcome message to channel",
+		"event_type", e.GetType().String(),
+		"person_id", e.Person.SlackMemberID,
+		"handler", WelcomeChannelHandler,
+		"channel", c.Slack.Onboarding.WelcomeChannelName,
+	)
+	return nil
+}
+
+func SlackOnboardingDirectMessageHandler(e PersonalEvent, c *config.Config, s slack.DirectMessenger) error {
+	var (
+		msg string
+		err error
+	)
+	switch e.GetType() {
+	case UpcomingFirstDay:
+		msg, err = renderTemplate(
+			e.PreReminder.MessageTemplate,
+			TemplateData{Person: e.Person, DaysBefore: e.PreReminder.DaysBefore},
+			e.Person.SlackMemberID,
+			e.PreReminder.DaysBefore,
+		)
+	case OnboardingMilestone:
+		msg, err = renderTemplate(
+			e.Milestone.MessageTemplate,
+			TemplateData{Person: e.Person},
+			e.Person.SlackMemberID,
+			e.Milestone.DaysAfter,
+		)
+	default:
+		return fmt.Errorf("Error when sending onboarding DM reminder: Invalid EventType: %d", e.GetType())
+	}
+	if err != nil {
+		return fmt.Errorf("Error when sending onboarding DM reminder: %w", err)
+	}
+
+	lead, _ := getLeadOrFallback(e.Person, c, e.OrgChart)
+	if lead == "" {
+		return skip("no lead of %s", e.Person.SlackMemberID)
+	}
+	if err := s.SendDirectMessage(lead, msg); err != nil {
+		return fmt.Errorf("Error when sending onboarding DM reminder: %w", err)
+	}
+	slog.Info(
+		"Sent onboarding reminder Slack DM to lead",
+		"event_type", e.GetType().String(),
+		"person_id", e.Person.SlackMemberID,
+		"handler", OnboardingDirectMessageHandler,
+		"lead_id", lead,
+	)
+	return nil
+}
+
+func SlackFarewellChannelHandler(e PersonalEvent, c *config.Config, s slack.ChannelMessenger) error {
+	if !e.Person.AllowsPublicPosts() {
+		return skip("privacy preferences of %s", e.Person.SlackMemberID)
+	}
+	msg, err := renderTemplate(
+		c.Slack.FarewellReminder.MessageTemplate,
+		TemplateData{Person: e.Person, Years: getYearsPassedToCurrentYear(e.Person.JoinDate)},
+		e.Person.SlackMemberID,
+	)
+	if err != nil {
+		return fmt.Errorf("Error when posting farewell message: %w", err)
+	}
+	if err := s.SendChannelMessage(c.Slack.FarewellReminder.ChannelName, msg); err != nil {
+		return fmt.Errorf("Error when posting farewell message: %w", err)
+	}
+	slog.Info(
+		"Sent farewell message to channel",
+		"event_type", e.GetType().String(),
+		"person_id", e.Person.SlackMemberID,
+		"handler", FarewellChannelHandler,
+		"channel", c.Slack.FarewellReminder.ChannelName,
+	)
+	return nil
+}
+
+func SlackFarewellDirectMessageHandler(e PersonalEvent, c *config.Config, s slack.DirectMessenger) error {
+	msg, err := renderTemplate(
+		e.PreReminder.MessageTemplate,
+		TemplateData{Person: e.Person, DaysBefore: e.PreReminder.DaysBefore},
+		e.Person.SlackMemberID,
+		e.PreReminder.DaysBefore,
+	)
+	if err != nil {
+		return fmt.Errorf("Error when sending farewell DM reminder: %w", err)
+	}
+
+	lead, _ := getLeadOrFallback(e.Person, c, e.OrgChart)
+	if lead == "" {
+		return skip("no lead of %s", e.Person.SlackMemberID)
+	}
+	if err := s.SendDirectMessage(lead, msg); err != nil {
+		return fmt.Errorf("Error when sending farewell DM reminder: %w", err)
+	}
+	slog.Info(
+		"Sent farewell reminder Slack DM to lead",
+		"event_type", e.GetType().String(),
+		"person_id", e.Person.SlackMemberID,
+		"handler", FarewellDirectMessageHandler,
+		"lead_id", lead,
+	)
+	return nil
+}
+
+// getDirectMessageRecipients returns management chain of person up to given depth followed by
+// always notified people, without duplicates and the person itself
+func getDirectMessageRecipients(p config.Person, c *config.Config, org *OrgChart, depth int, alwaysNotifySlackIds []string) []string {
+	seen := map[string]bool{p.SlackMemberID: true}
+	var recipients []string
+	add := func(slackMemberID string) {
+		if slackMemberID != "" && !seen[slackMemberID] {
+			seen[slackMemberID] = true
+			recipients = append(recipients, slackMemberID)
+		}
+	}
+
+	chain := getManagementChain(p, c, org, depth)
+	if len(chain) == 0 {
+		lead, _ := getLeadOrFallback(p, c, org)
+		chain = append(chain, lead)
+	}
+	for _, slackMemberID := range chain {
+		add(slackMemberID)
+	}
+	for _, slackMemberID := range alwaysNotifySlackIds {
+		add(slackMemberID)
+	}
+	return recipients
+}
+
+// OrgChart indexes leads of people and people who have left, built once per run
+type OrgChart struct {
+	leads map[string]*string
+	left  map[string]bool
+}
+
+func NewOrgChart(people []config.Person) *OrgChart {
+	leads := map[string]*string{}
+	for _, p := range people {
+		leads[p.SlackMemberID] = p.LeadSlackMemberID
+	}
+	return &OrgChart{leads: leads, left: getLeftSlackMemberIDs(people)}
+}
+
+// getManagementChain returns lead of person, lead of the lead etc. up to depth (at least direct lead),
+// skips deactivated leads and stops when the chain ends or loops
+func getManagementChain(p config.Person, c *config.Config, org *OrgChart, depth int) []string {
+	seen := map[string]bool{p.SlackMemberID: true}
+	var chain []string
+	lead := p.LeadSlackMemberID
+	for len(chain) < max(depth, 1) && lead != nil && *lead != "" && !seen[*lead] {
+		seen[*lead] = true
+		if !slices.Contains(c.Slack.FallbackRecipients.DeactivatedSlackMemberIDs, *lead) && !org.left[*lead] {
+			chain = append(chain, *lead)
+		}
+		lead = org.leads[*lead]
+	}
+	return chain
+}
+
+// getLeftSlackMemberIDs returns set of people who have left the company before today
+func getLeftSlackMemberIDs(people []config.Person) map[string]bool {
+	left := map[string]bool{}
+	for _, p := range people {
+		if p.HasLeft(getToday()) {
+			left[p.SlackMemberID] = true
+		}
+	}
+	return left
+}
+
+// getLeadOrFallback returns lead of person or, when lead is missing or deactivated, first active lead up
+// the chain, HR partner of person's team or default recipient (empty when none is configured) along
+// with warning describing why fallback was used
+func getLeadOrFallback(p config.Person, c *config.Config, org *OrgChart) (string, string) {
+	var warning string
+	switch {
+	case p.LeadSlackMemberID == nil || *p.LeadSlackMemberID == "":
+		warning = fmt.Sprintf("Missing lead of %s", p.SlackMemberID)
+	case slices.Contains(c.Slack.FallbackRecipients.DeactivatedSlackMemberIDs, *p.LeadSlackMemberID):
+		warning = fmt.Sprintf("Deactivated lead %s of %s", *p.LeadSlackMemberID, p.SlackMemberID)
+	case org.left[*p.LeadSlackMemberID]:
+		warning = fmt.Sprintf("Lead %s of %s has left", *p.LeadSlackMemberID, p.SlackMemberID)
+	}
+
+	if chain := getManagementChain(p, c, org, 1); len(chain) > 0 {
+		return chain[0], warning
+	}
+	if hrPartner := c.Slack.FallbackRecipients.TeamHRPartners[p.Team]; p.Team != "" && hrPartner != "" {
+		return hrPartner, warning
+	}
+	return c.Slack.FallbackRecipients.DefaultSlackMemberID, warning
+}
+
+func SlackBirthdayPersonalReminderHandler(e PersonalEvent, c *config.Config, s slack.PersonalReminderSetter) error {
+	lead, _ := getLeadOrFallback(e.Person, c, e.OrgChart)
+	if lead == "" {
+		return skip("no lead of %s", e.Person.SlackMemberID)
+	}
+	if !e.Person.IsCelebrated() {
+		return skip("privacy preferences of %s", e.Person.SlackMemberID)
+	}
+	msg, err := renderTemplate(
+		c.Slack.BirthdaysPersonalReminder.MessageTemplate,
+		TemplateData{Person: e.Person},
+		e.Person.SlackMemberID,
+	)
+	if err != nil {
+		return fmt.Errorf("Error when posting Slack reminder: %w", err)
 	}
 	if err := s.SetPersonalReminder(
-		*e.Person.LeadSlackMemberID,
+		lead,
 		c.Slack.BirthdaysPersonalReminder.Time,
-		fmt.Sprintf(c.Slack.BirthdaysPersonalReminder.MessageTemplate, e.Person.SlackMemberID),
+		msg,
 	); err != nil {
-		log.Println("Error when posting Slack reminder:", err)
-		return
+		return fmt.Errorf("Error when posting Slack reminder: %w", err)
 	}
-	log.Println("Set birthday Slack reminder for lead", *e.Person.LeadSlackMemberID)
+	slog.Info(
+		"Set birthday Slack reminder for lead",
+		"event_type", e.GetType().String(),
+		"person_id", e.Person.SlackMemberID,
+		"handler", BirthdayPersonalReminderHandler,
+		"lead_id", lead,
+	)
+	return nil
 }
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+var (
+	logLevel  string
+	logFormat string
+)
+
+// InitLogger sets default slog logger writing to given output with level (debug, info, warn, error) and format (text, json)
+func InitLogger(w io.Writer, level string, format string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("Invalid log level %q", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: l}
+
+	switch strings.ToLower(format) {
+	case "text":
+		slog.SetDefault(slog.New(slog.NewTextHandler(w, opts)))
+	case "json":
+		slog.SetDefault(slog.New(slog.NewJSONHandler(w, opts)))
+	default:
+		return fmt.Errorf("Invalid log format %q", format)
+	}
+	return nil
+}
+
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// getEventAttrs returns log fields describing event
+func getEventAttrs(e Event) []any {
+	attrs := []any{"event_type", e.GetType().String()}
+	if pe, ok := e.(PersonalEvent); ok {
+		attrs = append(attrs, "person_id", pe.Person.SlackMemberID)
+		if pe.CustomEvent != nil {
+			attrs = append(attrs, "custom_event", pe.CustomEvent.Name)
+		}
+	}
+	if ge, ok := e.(GroupEvent); ok {
+		attrs = append(attrs, "person_ids", strings.Join(ge.getSlackMemberIDs(), ","))
+	}
+	if ce, ok := e.(CompanyEvent); ok {
+		attrs = append(attrs, "company_event", ce.CompanyEvent.Name)
+	}
+	if re, ok := e.(ReportEvent); ok && re.LeadSlackMemberID != "" {
+		attrs = append(attrs, "lead_id", re.LeadSlackMemberID)
+	}
+	return attrs
+}
@@ -8,30 +8,70 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var rootCmd = &cobra.Command{
-	Use:   "celebrations",
-	Short: "Celebrate your company birthdays and anniversaries",
-	Long:  "Set of tools facilitating company anniversaries and birthdays",
-	PreRunE: func(cmd *cobra.Command, args []string) error {
-		if len(args) == 0 {
-			cmd.Help()
-			os.Exit(0)
-		}
-		return nil
-	},
-	Run: func(cmd *cobra.Command, args []string) {},
+// newRootCmd returns app command with all subcommands, flags bound to their package variables are reset to defaults
+func newRootCmd() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "celebrations",
+		Short: "Celebrate your company birthdays and anniversaries",
+		Long:  "Set of tools facilitating company anniversaries and birthdays",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				cmd.Help()
+				os.Exit(0)
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {},
+	}
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "", "Log level: debug, info, warn or error (overrides log.level from config, default info)")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "Log format: text or json (overrides log.format from config, default text)")
+	rootCmd.Root().CompletionOptions.DisableDefaultCmd = true
+	rootCmd.AddCommand(newDownloadUsersCmd())
+	rootCmd.AddCommand(newSendRemindersCmd())
+	rootCmd.AddCommand(newServeCmd())
+	rootCmd.AddCommand(newDoctorCmd())
+	rootCmd.AddCommand(newVersionCmd())
+	return rootCmd
 }
 
 func init() {
-	cobra.OnInitialize(func() { config.InitConfig("config") })
-	rootCmd.Root().CompletionOptions.DisableDefaultCmd = true
-	rootCmd.AddCommand(DownloadUsers)
-	rootCmd.AddCommand(SendRemindersCmd)
-	rootCmd.AddCommand(VersionCmd)
+	cobra.OnInitialize(initConfigAndLogger)
+}
+
+func initConfigAndLogger() {
+	if err := InitLogger(os.Stderr, firstNonEmpty(logLevel, "info"), firstNonEmpty(logFormat, "text")); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if err := config.InitConfig("config"); err != nil {
+		fatal("Invalid config", "error", err)
+	}
+
+	c, err := config.GetConfig()
+	if err != nil {
+		fatal("Invalid config", "error", err)
+	}
+	if err := InitLogger(
+		os.Stderr,
+		firstNonEmpty(logLevel, c.Log.Level, "info"),
+		firstNonEmpty(logFormat, c.Log.Format, "text"),
+	); err != nil {
+		fatal("Error initializing logger", "error", err)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	if err := newRootCmd().Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
@@ -1,27 +1,93 @@
 package cmd
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/nomysz/celebrations/config"
+	"github.com/nomysz/celebrations/metrics"
+	"github.com/nomysz/celebrations/preferences"
 	"github.com/nomysz/celebrations/slack"
 	"github.com/spf13/cobra"
 )
 
-var SendRemindersCmd = &cobra.Command{
-	Use:   "send-reminders",
-	Short: "Send remidners via configured handlers",
-	Long:  "Send remidners via configured handlers",
-	Run: func(cmd *cobra.Command, args []string) {
-		cfg := config.GetConfig()
-		SendReminders(
-			cfg,
-			slack.NewClient(cfg.Slack.BotToken, cfg.Slack.UserToken),
-		)
-	},
+var (
+	metricsTextfile string
+	preflight       bool
+)
+
+func newSendRemindersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "send-reminders",
+		Short:        "Send remidners via configured handlers",
+		Long:         "Send remidners via configured handlers, exits with non-zero status when any of them failed",
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.GetConfig()
+			if err != nil {
+				return err
+			}
+			sc := newSlackClient(cfg)
+			if preflight {
+				if err := Preflight(cfg, sc); err != nil {
+					return fmt.Errorf("Preflight failed, nothing was sent: %w", err)
+				}
+			}
+			err = SendReminders(cfg, sc)
+			if metricsTextfile != "" {
+				if err := metrics.Default.WriteTextfile(metricsTextfile); err != nil {
+					slog.Error("Error writing metrics", "filename", metricsTextfile, "error", err)
+				}
+			}
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&preflight, "preflight", false, "Verify Slack setup (see doctor command) before sending anything and reuse channel IDs resolved by it (off by default)")
+	cmd.Flags().StringVar(&metricsTextfile, "metrics-textfile", "", "Write metrics to file for node-exporter textfile collector (e.g. /var/lib/node_exporter/celebrations.prom)")
+	return cmd
+}
+
+func newHTTPClient(c *config.Config) *http.Client {
+	httpClient, err := slack.NewHTTPClient(c.Slack.HTTP.Proxy, c.Slack.HTTP.CABundle, c.Slack.HTTP.Timeout)
+	if err != nil {
+		fatal("Invalid slack.http config", "error", err)
+	}
+	return httpClient
 }
 
+func newSlackClient(c *config.Config) *slack.Client {
+	return slack.NewClient(
+		c.Slack.BotToken,
+		c.Slack.UserToken,
+		slack.WithMaxAttempts(c.Slack.Retry.MaxAttempts),
+		slack.WithInitialBackoff(c.Slack.Retry.InitialBackoff),
+		slack.WithAPIURL(c.Slack.HTTP.APIURL),
+		slack.WithHTTPClient(newHTTPClient(c)),
+	)
+}
+
+const (
+	MonthlyReportHandler                 = "monthly_report"
+	AnniversaryChannelHandler            = "anniversary_channel_reminder"
+	BirthdayReminderChannelHandler       = "birthdays_channel_reminder"
+	BirthdayReminderDirectMessageHandler = "birthdays_direct_message_reminder"
+	BirthdayPersonalReminderHandler      = "birthdays_personal_reminder"
+	WeeklyDigestHandler                  = "weekly_digest"
+	LeadDigestHandler                    = "lead_digest"
+	AnniversaryDirectMessageHandler      = "anniversaries_direct_message_reminder"
+	CustomEventChannelHandler            = "custom_event_channel_reminder"
+	CustomEventDirectMessageHandler      = "custom_event_direct_message_reminder"
+	CompanyEventChannelHandler           = "company_event_channel_reminder"
+	WelcomeChannelHandler                = "onboarding_welcome"
+	OnboardingDirectMessageHandler       = "onboarding_direct_message_reminder"
+	FarewellChannelHandler               = "farewell_channel_reminder"
+	FarewellDirectMessageHandler         = "farewell_direct_message_reminder"
+)
+
 type EventType uint16
 
 const (
@@ -29,8 +95,55 @@ const (
 	Birthday
 	UpcomingBirthday
 	MonthlyReportDay
+	WeeklyDigestDay
+	LeadDigestDay
+	UpcomingAnniversary
+	CustomEventDay
+	UpcomingCustomEvent
+	CompanyEventDay
+	FirstDay
+	UpcomingFirstDay
+	OnboardingMilestone
+	Farewell
+	UpcomingFarewell
 )
 
+func (t EventType) String() string {
+	switch t {
+	case Anniversary:
+		return "anniversary"
+	case Birthday:
+		return "birthday"
+	case UpcomingBirthday:
+		return "upcoming_birthday"
+	case MonthlyReportDay:
+		return "monthly_report"
+	case WeeklyDigestDay:
+		return "weekly_digest"
+	case LeadDigestDay:
+		return "lead_digest"
+	case UpcomingAnniversary:
+		return "upcoming_anniversary"
+	case CustomEventDay:
+		return "custom_event"
+	case UpcomingCustomEvent:
+		return "upcoming_custom_event"
+	case CompanyEventDay:
+		return "company_event"
+	case FirstDay:
+		return "first_day"
+	case UpcomingFirstDay:
+		return "upcoming_first_day"
+	case OnboardingMilestone:
+		return "onboarding_milestone"
+	case Farewell:
+		return "farewell"
+	case UpcomingFarewell:
+		return "upcoming_farewell"
+	}
+	return fmt.Sprintf("unknown(%d)", uint16(t))
+}
+
 type Event interface {
 	GetType() EventType
 }
@@ -38,89 +151,435 @@ type Event interface {
 type PersonalEvent struct {
 	Type   EventType
 	Person config.Person
+	// Pre-reminder the event was generated for (upcoming events only)
+	PreReminder *config.PreReminder
+	// Custom event definition (custom events only)
+	CustomEvent *config.CustomEvent
+	// Milestone the event was generated for (onboarding milestones only)
+	Milestone *config.Milestone
+	// Leads of all people, shared by events of single run
+	OrgChart *OrgChart
 }
 
 func (e PersonalEvent) GetType() EventType {
 	return e.Type
 }
 
-type MonthlyReportEvent struct {
-	Type          EventType
-	Birthdays     []config.Person
-	Anniversaries []config.Person
+// GroupEvent lists people celebrating the same day, posted to channel as a single message
+type GroupEvent struct {
+	Type   EventType
+	People []config.Person
 }
 
-func (e MonthlyReportEvent) GetType() EventType {
+func (e GroupEvent) GetType() EventType {
 	return e.Type
 }
 
-func SendReminders(c *config.Config, sc slack.SlackCommunicator) {
-	log.Println(len(c.People), "people found in config.")
+func (e GroupEvent) getSlackMemberIDs() []string {
+	var ids []string
+	for _, p := range e.People {
+		ids = append(ids, p.SlackMemberID)
+	}
+	return ids
+}
+
+type CompanyEvent struct {
+	Type         EventType
+	CompanyEvent config.CompanyEvent
+}
+
+func (e CompanyEvent) GetType() EventType {
+	return e.Type
+}
+
+// ReportEvent lists people celebrating between From (inclusive) and To (exclusive)
+type ReportEvent struct {
+	Type EventType
+	// Slack ID of lead whose direct reports are listed (lead digest only)
+	LeadSlackMemberID string
+	From              time.Time
+	To                time.Time
+	Birthdays         []config.Person
+	Anniversaries     []config.Person
+	// Custom events, people celebrating enabled ones (monthly report and weekly digest only)
+	CustomEvents []CustomEventReport
+	// Company events within report period (monthly report and weekly digest only)
+	CompanyEvents []config.CompanyEvent
+}
+
+// CustomEventReport lists people celebrating custom event within report period
+type CustomEventReport struct {
+	Event  config.CustomEvent
+	People []config.Person
+}
+
+func (e ReportEvent) GetType() EventType {
+	return e.Type
+}
+
+// SendReminders runs enabled handlers for todays events, returns joined errors of failed handlers
+func SendReminders(c *config.Config, sc slack.SlackCommunicator) error {
+	summary, err := sendReminders(c, sc)
+	if err != nil {
+		return err
+	}
+
+	summary.Log()
+
+	if err := summary.Err(); err != nil {
+		return err
+	}
+	metrics.Default.RunSucceeded(GetNow())
+	return nil
+}
+
+// sendReminders runs enabled handlers for todays events, returns summary of their results
+func sendReminders(c *config.Config, sc slack.SlackCommunicator) (*Summary, error) {
+	slog.Info("People found in config", "count", len(c.People))
+
+	if c.Server.PreferencesFile != "" {
+		store, err := preferences.Load(c.Server.PreferencesFile)
+		if err != nil {
+			return nil, err
+		}
+		store.Apply(c.People)
+	}
+
+	people := getCurrentPeople(c.People)
+	slog.Info("People who have left skipped", "count", len(c.People)-len(people))
+
+	org := NewOrgChart(c.People)
 
 	var todaysEvents []Event
 
-	for _, p := range c.People {
+	for _, p := range people {
+		if !p.IsCelebrated() {
+			continue
+		}
 		for e := range GetTodaysEventsForPerson(p, c) {
 			todaysEvents = append(todaysEvents, e)
 		}
 	}
 
-	if GetNow().Day() == 1 && c.Slack.MonthlyReport.Enabled {
-		todaysEvents = append(todaysEvents, GetMonthlyReportEvent(c.People))
+	if c.Slack.MonthlyReport.Enabled && GetNow().Day() == c.Slack.MonthlyReport.GetDayOfMonth() {
+		e := GetMonthlyReportEvent(people, c.Slack.MonthlyReport)
+		e.CustomEvents = getCustomEventReports(people, c.Slack.CustomEvents, e.From, e.To)
+		e.CompanyEvents = getCompanyEventsBetween(c.Slack.CompanyEvents, e.From, e.To)
+		todaysEvents = append(todaysEvents, e)
+	}
+
+	if c.Slack.WeeklyDigest.Enabled && GetNow().Weekday() == c.Slack.WeeklyDigest.GetWeekday() {
+		e := GetWeeklyDigestEvent(people, c.Slack.WeeklyDigest)
+		e.CustomEvents = getCustomEventReports(people, c.Slack.CustomEvents, e.From, e.To)
+		e.CompanyEvents = getCompanyEventsBetween(c.Slack.CompanyEvents, e.From, e.To)
+		todaysEvents = append(todaysEvents, e)
+	}
+
+	if c.Slack.LeadDigest.Enabled && GetNow().Weekday() == c.Slack.LeadDigest.GetWeekday() {
+		for _, e := range GetLeadDigestEvents(people, c, org) {
+			todaysEvents = append(todaysEvents, e)
+		}
+	}
+
+	for _, ce := range getCompanyEventsBetween(c.Slack.CompanyEvents, getToday(), getToday().AddDate(0, 0, 1)) {
+		todaysEvents = append(todaysEvents, CompanyEvent{Type: CompanyEventDay, CompanyEvent: ce})
+	}
+
+	summary := NewSummary()
+
+	for _, e := range todaysEvents {
+		metrics.Default.EventDetected(e.GetType().String())
 	}
 
+	// Same day celebrations of people allowing channel posts, when grouping is configured
+	groupedAnniversaries := GroupEvent{Type: Anniversary}
+	groupedBirthdays := GroupEvent{Type: Birthday}
+
 	for _, e := range todaysEvents {
 		if pe, ok := e.(PersonalEvent); ok {
+			pe.OrgChart = org
+			if notifiesLead(pe, c) {
+				if _, warning := getLeadOrFallback(pe.Person, c, org); warning != "" {
+					summary.Warn(pe, warning)
+				}
+			}
 			switch e.GetType() {
 			case Anniversary:
 				if c.Slack.AnniversaryChannelReminder.Enabled {
-					SlackAnniversaryChannelHandler(pe, c, sc)
+					if c.Slack.AnniversaryChannelReminder.GroupedMessageTemplate != "" && pe.Person.AllowsPublicPosts() {
+						groupedAnniversaries.People = append(groupedAnniversaries.People, pe.Person)
+					} else {
+						summary.Record(AnniversaryChannelHandler, pe, SlackAnniversaryChannelHandler(pe, c, countSent(AnniversaryChannelHandler, sc)))
+					}
+				}
+				if c.Slack.AnniversariesDirectMessageReminder.Enabled && c.Slack.AnniversariesDirectMessageReminder.MessageTemplate != "" {
+					summary.Record(AnniversaryDirectMessageHandler, pe, SlackAnniversaryReminderDirectMessageHandler(pe, c, countSent(AnniversaryDirectMessageHandler, sc)))
 				}
 			case Birthday:
 				if c.Slack.BirthdaysChannelReminder.Enabled {
-					SlackBirthdayReminderChannelHandler(pe, c, sc)
+					if c.Slack.BirthdaysChannelReminder.GroupedMessageTemplate != "" && pe.Person.AllowsChannelPosts() {
+						groupedBirthdays.People = append(groupedBirthdays.People, pe.Person)
+					} else {
+						summary.Record(BirthdayReminderChannelHandler, pe, SlackBirthdayReminderChannelHandler(pe, c, countSent(BirthdayReminderChannelHandler, sc)))
+					}
 				}
 				if c.Slack.BirthdaysDirectMessageReminder.Enabled {
-					SlackBirthdayReminderDirectMessageHandler(pe, c, sc)
+					summary.Record(BirthdayReminderDirectMessageHandler, pe, SlackBirthdayReminderDirectMessageHandler(pe, c, countSent(BirthdayReminderDirectMessageHandler, sc)))
 				}
 				if c.Slack.BirthdaysPersonalReminder.Enabled {
-					SlackBirthdayPersonalReminderHandler(pe, c, sc)
+					summary.Record(BirthdayPersonalReminderHandler, pe, SlackBirthdayPersonalReminderHandler(pe, c, countSent(BirthdayPersonalReminderHandler, sc)))
 				}
 			case UpcomingBirthday:
 				if c.Slack.BirthdaysDirectMessageReminder.Enabled {
-					SlackBirthdayReminderDirectMessageHandler(pe, c, sc)
+					summary.Record(BirthdayReminderDirectMessageHandler, pe, SlackBirthdayReminderDirectMessageHandler(pe, c, countSent(BirthdayReminderDirectMessageHandler, sc)))
+				}
+			case UpcomingAnniversary:
+				if c.Slack.AnniversariesDirectMessageReminder.Enabled {
+					summary.Record(AnniversaryDirectMessageHandler, pe, SlackAnniversaryReminderDirectMessageHandler(pe, c, countSent(AnniversaryDirectMessageHandler, sc)))
+				}
+			case CustomEventDay:
+				if pe.CustomEvent.ChannelName != "" {
+					summary.Record(CustomEventChannelHandler, pe, SlackCustomEventChannelHandler(pe, c, countSent(CustomEventChannelHandler, sc)))
+				}
+			case UpcomingCustomEvent:
+				summary.Record(CustomEventDirectMessageHandler, pe, SlackCustomEventDirectMessageHandler(pe, c, countSent(CustomEventDirectMessageHandler, sc)))
+			case FirstDay:
+				if c.Slack.Onboarding.Enabled && c.Slack.Onboarding.WelcomeChannelName != "" {
+					summary.Record(WelcomeChannelHandler, pe, SlackWelcomeChannelHandler(pe, c, countSent(WelcomeChannelHandler, sc)))
+				}
+			case UpcomingFirstDay, OnboardingMilestone:
+				if c.Slack.Onboarding.Enabled {
+					summary.Record(OnboardingDirectMessageHandler, pe, SlackOnboardingDirectMessageHandler(pe, c, countSent(OnboardingDirectMessageHandler, sc)))
+				}
+			case Farewell:
+				if c.Slack.FarewellReminder.Enabled && c.Slack.FarewellReminder.ChannelName != "" {
+					summary.Record(FarewellChannelHandler, pe, SlackFarewellChannelHandler(pe, c, countSent(FarewellChannelHandler, sc)))
+				}
+			case UpcomingFarewell:
+				if c.Slack.FarewellReminder.Enabled {
+					summary.Record(FarewellDirectMessageHandler, pe, SlackFarewellDirectMessageHandler(pe, c, countSent(FarewellDirectMessageHandler, sc)))
 				}
 			}
-		} else if me, ok := e.(MonthlyReportEvent); ok {
-			if c.Slack.MonthlyReport.Enabled {
-				SlackMonthlyReportHandler(me, c, sc)
+		} else if re, ok := e.(ReportEvent); ok {
+			switch e.GetType() {
+			case MonthlyReportDay:
+				summary.Record(MonthlyReportHandler, re, SlackMonthlyReportHandler(re, c, countSent(MonthlyReportHandler, sc)))
+			case WeeklyDigestDay:
+				summary.Record(WeeklyDigestHandler, re, SlackWeeklyDigestHandler(re, c, countSent(WeeklyDigestHandler, sc)))
+			case LeadDigestDay:
+				for _, warning := range getLeadDigestWarnings(re, c, org) {
+					summary.Warn(re, warning)
+				}
+				summary.Record(LeadDigestHandler, re, SlackLeadDigestHandler(re, c, countSent(LeadDigestHandler, sc)))
 			}
+		} else if ce, ok := e.(CompanyEvent); ok {
+			summary.Record(CompanyEventChannelHandler, ce, SlackCompanyEventChannelHandler(ce, c, countSent(CompanyEventChannelHandler, sc)))
 		} else {
 			panic("Unknown type of event to handle")
 		}
 	}
+
+	// Single person of the day is posted with regular message template
+	switch len(groupedAnniversaries.People) {
+	case 0:
+	case 1:
+		pe := PersonalEvent{Type: Anniversary, Person: groupedAnniversaries.People[0]}
+		summary.Record(AnniversaryChannelHandler, pe, SlackAnniversaryChannelHandler(pe, c, countSent(AnniversaryChannelHandler, sc)))
+	default:
+		summary.Record(AnniversaryChannelHandler, groupedAnniversaries, SlackAnniversaryGroupChannelHandler(groupedAnniversaries, c, countSent(AnniversaryChannelHandler, sc)))
+	}
+	switch len(groupedBirthdays.People) {
+	case 0:
+	case 1:
+		pe := PersonalEvent{Type: Birthday, Person: groupedBirthdays.People[0]}
+		summary.Record(BirthdayReminderChannelHandler, pe, SlackBirthdayReminderChannelHandler(pe, c, countSent(BirthdayReminderChannelHandler, sc)))
+	default:
+		summary.Record(BirthdayReminderChannelHandler, groupedBirthdays, SlackBirthdayGroupChannelHandler(groupedBirthdays, c, countSent(BirthdayReminderChannelHandler, sc)))
+	}
+
+	return summary, nil
+}
+
+// notifiesLead returns true when any of enabled handlers of event notifies lead of person
+func notifiesLead(e PersonalEvent, c *config.Config) bool {
+	switch e.GetType() {
+	case Birthday:
+		return c.Slack.BirthdaysDirectMessageReminder.Enabled || c.Slack.BirthdaysPersonalReminder.Enabled
+	case UpcomingBirthday:
+		return c.Slack.BirthdaysDirectMessageReminder.Enabled
+	case Anniversary:
+		return c.Slack.AnniversariesDirectMessageReminder.Enabled && c.Slack.AnniversariesDirectMessageReminder.MessageTemplate != ""
+	case UpcomingAnniversary:
+		return c.Slack.AnniversariesDirectMessageReminder.Enabled
+	case UpcomingCustomEvent:
+		return true
+	case UpcomingFirstDay, OnboardingMilestone:
+		return c.Slack.Onboarding.Enabled
+	case UpcomingFarewell:
+		return c.Slack.FarewellReminder.Enabled
+	}
+	return false
 }
 
-func GetMonthlyReportEvent(p []config.Person) MonthlyReportEvent {
-	var birthdaysThisMonth,
-		anniversariesThisMonth []config.Person
+// GetMonthlyReportEvent returns people celebrating in current month, next month or next days depending
+// on report scope
+func GetMonthlyReportEvent(p []config.Person, r config.MonthlyReport) ReportEvent {
+	today := getToday()
+	firstDayOfMonth := today.AddDate(0, 0, 1-today.Day())
 
-	currentMonth := GetNow().Month()
+	from, to := firstDayOfMonth, firstDayOfMonth.AddDate(0, 1, 0)
+	switch r.Scope {
+	case config.NextMonthScope:
+		from, to = to, to.AddDate(0, 1, 0)
+	case config.NextDaysScope:
+		from, to = today, today.AddDate(0, 0, r.DaysAhead)
+	}
+
+	return getReportEvent(p, MonthlyReportDay, from, to, config.Person.AllowsChannelPosts)
+}
+
+// GetWeeklyDigestEvent returns people celebrating in the coming days starting today
+func GetWeeklyDigestEvent(p []config.Person, d config.WeeklyDigest) ReportEvent {
+	today := getToday()
+	return getReportEvent(p, WeeklyDigestDay, today, today.AddDate(0, 0, d.GetDaysAhead()), config.Person.AllowsChannelPosts)
+}
+
+// GetLeadDigestEvents returns event for each lead listing their direct reports celebrating in the coming
+// days starting today, reports of missing or deactivated leads are listed to fallback recipients
+func GetLeadDigestEvents(p []config.Person, c *config.Config, org *OrgChart) []ReportEvent {
+	today := getToday()
+	d := c.Slack.LeadDigest
+
+	reports := map[string][]config.Person{}
+	var leads []string
+	for _, p := range p {
+		lead, _ := getLeadOrFallback(p, c, org)
+		if lead == "" {
+			continue
+		}
+		if _, ok := reports[lead]; !ok {
+			leads = append(leads, lead)
+		}
+		reports[lead] = append(reports[lead], p)
+	}
+
+	var events []ReportEvent
+	for _, lead := range leads {
+		e := getReportEvent(reports[lead], LeadDigestDay, today, today.AddDate(0, 0, d.GetDaysAhead()), config.Person.IsCelebrated)
+		e.LeadSlackMemberID = lead
+		events = append(events, e)
+	}
+	return events
+}
+
+// getLeadDigestWarnings returns warnings of people listed in lead digest sent to fallback recipient
+func getLeadDigestWarnings(e ReportEvent, c *config.Config, org *OrgChart) []string {
+	var warnings []string
+	seen := map[string]bool{}
+	for _, p := range append(slices.Clone(e.Birthdays), e.Anniversaries...) {
+		if seen[p.SlackMemberID] {
+			continue
+		}
+		seen[p.SlackMemberID] = true
+		if _, warning := getLeadOrFallback(p, c, org); warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+func getReportEvent(
+	p []config.Person,
+	t EventType,
+	from time.Time,
+	to time.Time,
+	include func(config.Person) bool,
+) ReportEvent {
+	var birthdays,
+		anniversaries []config.Person
 
 	for _, p := range p {
-		if p.BirthDate.Month() == currentMonth {
-			birthdaysThisMonth = append(birthdaysThisMonth, p)
+		if !include(p) || !p.HasJoined(getToday()) {
+			continue
+		}
+		if getNextOccurrence(p.BirthDate, from).Before(to) {
+			birthdays = append(birthdays, p)
+		}
+		if occurrence := getNextOccurrence(p.JoinDate, from); occurrence.Before(to) && occurrence.Year() > p.JoinDate.Year() {
+			anniversaries = append(anniversaries, p)
+		}
+	}
+
+	return ReportEvent{
+		Type:          t,
+		From:          from,
+		To:            to,
+		Birthdays:     birthdays,
+		Anniversaries: anniversaries,
+	}
+}
+
+// getCustomEventReports returns people celebrating custom events between from (inclusive) and to (exclusive),
+// disabled events are listed without people so that templates referring them still render
+func getCustomEventReports(p []config.Person, customEvents []config.CustomEvent, from time.Time, to time.Time) []CustomEventReport {
+	var reports []CustomEventReport
+	for _, ce := range customEvents {
+		r := CustomEventReport{Event: ce}
+		if !ce.Enabled {
+			reports = append(reports, r)
+			continue
+		}
+		for _, p := range p {
+			date, ok := p.Dates[ce.DateField]
+			if ok && p.AllowsChannelPosts() && p.HasJoined(getToday()) && getNextOccurrence(date, from).Before(to) {
+				r.People = append(r.People, p)
+			}
+		}
+		reports = append(reports, r)
+	}
+	return reports
+}
+
+// getCompanyEventsBetween returns enabled company events taking place between from (inclusive) and to
+// (exclusive) sorted by date
+func getCompanyEventsBetween(companyEvents []config.CompanyEvent, from time.Time, to time.Time) []config.CompanyEvent {
+	var events []config.CompanyEvent
+	for _, ce := range companyEvents {
+		if !ce.Enabled {
+			continue
 		}
-		if p.JoinDate.Month() == currentMonth {
-			anniversariesThisMonth = append(anniversariesThisMonth, p)
+		date := getCompanyEventDate(ce, from)
+		if !date.Before(from) && date.Before(to) {
+			events = append(events, ce)
 		}
 	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return getCompanyEventDate(events[i], from).Before(getCompanyEventDate(events[j], from))
+	})
+	return events
+}
+
+// getCompanyEventDate returns date of one-off company event or first occurrence of yearly one on or after from
+func getCompanyEventDate(ce config.CompanyEvent, from time.Time) time.Time {
+	if ce.Yearly {
+		return getNextOccurrence(ce.Date, from)
+	}
+	return time.Date(ce.Date.Year(), ce.Date.Month(), ce.Date.Day(), 0, 0, 0, 0, from.Location())
+}
 
-	return MonthlyReportEvent{
-		Type:          MonthlyReportDay,
-		Birthdays:     birthdaysThisMonth,
-		Anniversaries: anniversariesThisMonth,
+// getToday returns beginning of current day
+func getToday() time.Time {
+	now := GetNow()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+// getNextOccurrence returns first day and month of date (e.g. birthday) on or after from
+func getNextOccurrence(date time.Time, from time.Time) time.Time {
+	t := time.Date(from.Year(), date.Month(), date.Day(), 0, 0, 0, 0, from.Location())
+	if t.Before(from) {
+		t = t.AddDate(1, 0, 0)
 	}
+	return t
 }
 
 func GetTodaysEventsForPerson(
@@ -130,14 +589,53 @@ func GetTodaysEventsForPerson(
 	ch := make(chan Event)
 	go func() {
 		defer close(ch)
-		if DayAndMonthMatch(
-			p.BirthDate.Add(
-				-time.Hour * 24 * time.Duration(c.Slack.BirthdaysDirectMessageReminder.PreReminderDaysBefore),
-			),
-		) {
+		// People yet to start are only onboarded
+		if !p.HasJoined(getToday()) {
+			for _, e := range getOnboardingEvents(p, c) {
+				ch <- e
+			}
+			return
+		}
+		for _, r := range getDuePreReminders(p.BirthDate, c.Slack.BirthdaysDirectMessageReminder.GetPreReminders(), c.Calendar) {
+			r := r
 			ch <- PersonalEvent{
-				Type:   UpcomingBirthday,
-				Person: p,
+				Type:        UpcomingBirthday,
+				Person:      p,
+				PreReminder: &r,
+			}
+		}
+		for _, r := range getDuePreReminders(p.JoinDate, c.Slack.AnniversariesDirectMessageReminder.PreReminders, c.Calendar) {
+			r := r
+			if getNextOccurrence(p.JoinDate, getToday()).Year() <= p.JoinDate.Year() {
+				continue
+			}
+			ch <- PersonalEvent{
+				Type:        UpcomingAnniversary,
+				Person:      p,
+				PreReminder: &r,
+			}
+		}
+		for _, ce := range c.Slack.CustomEvents {
+			ce := ce
+			date, ok := p.Dates[ce.DateField]
+			if !ce.Enabled || !ok {
+				continue
+			}
+			for _, r := range getDuePreReminders(date, ce.PreReminders, c.Calendar) {
+				r := r
+				ch <- PersonalEvent{
+					Type:        UpcomingCustomEvent,
+					Person:      p,
+					PreReminder: &r,
+					CustomEvent: &ce,
+				}
+			}
+			if DayAndMonthMatch(date) {
+				ch <- PersonalEvent{
+					Type:        CustomEventDay,
+					Person:      p,
+					CustomEvent: &ce,
+				}
 			}
 		}
 		if DayAndMonthMatch(p.BirthDate) {
@@ -146,16 +644,108 @@ func GetTodaysEventsForPerson(
 				Person: p,
 			}
 		}
-		if DayAndMonthMatch(p.JoinDate) {
+		// First day is welcomed by onboarding instead, when enabled
+		if DayAndMonthMatch(p.JoinDate) && !(c.Slack.Onboarding.Enabled && getYearsPassedToCurrentYear(p.JoinDate) == 0) {
 			ch <- PersonalEvent{
 				Type:   Anniversary,
 				Person: p,
 			}
 		}
+		for _, e := range getOnboardingEvents(p, c) {
+			ch <- e
+		}
+		for _, e := range getFarewellEvents(p, c) {
+			ch <- e
+		}
 	}()
 	return ch
 }
 
+// getDuePreReminders returns pre-reminders of yearly date (e.g. birthday) due today
+func getDuePreReminders(date time.Time, preReminders []config.PreReminder, cal config.Calendar) []config.PreReminder {
+	today := getToday()
+	occurrence := getNextOccurrence(date, today)
+	var due []config.PreReminder
+	for _, r := range preReminders {
+		remindOn := occurrence.AddDate(0, 0, -r.DaysBefore)
+		if r.Unit == config.BusinessDaysUnit {
+			remindOn = cal.SubtractBusinessDays(occurrence, r.DaysBefore)
+		}
+		if remindOn.Equal(today) {
+			due = append(due, r)
+		}
+	}
+	return due
+}
+
+// getDueOneOffPreReminders returns pre-reminders of one-off date (e.g. first day) due today
+func getDueOneOffPreReminders(date time.Time, preReminders []config.PreReminder, cal config.Calendar) []config.PreReminder {
+	today := getToday()
+	if !date.After(today) || date.Year() != getNextOccurrence(date, today).Year() {
+		return nil
+	}
+	return getDuePreReminders(date, preReminders, cal)
+}
+
+// getLocalDate returns date (stored in UTC) at midnight of local time, comparable with getToday
+func getLocalDate(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, getToday().Location())
+}
+
+// getFarewellEvents returns events of last day of person and reminders before it
+func getFarewellEvents(p config.Person, c *config.Config) []PersonalEvent {
+	if !c.Slack.FarewellReminder.Enabled || p.LeaveDate.IsZero() {
+		return nil
+	}
+	lastDay := getLocalDate(p.LeaveDate)
+
+	var events []PersonalEvent
+	for _, r := range getDueOneOffPreReminders(lastDay, c.Slack.FarewellReminder.PreReminders, c.Calendar) {
+		r := r
+		events = append(events, PersonalEvent{Type: UpcomingFarewell, Person: p, PreReminder: &r})
+	}
+	if lastDay.Equal(getToday()) {
+		events = append(events, PersonalEvent{Type: Farewell, Person: p})
+	}
+	return events
+}
+
+// getCurrentPeople returns people who have not left the company before today
+func getCurrentPeople(people []config.Person) []config.Person {
+	var current []config.Person
+	for _, p := range people {
+		if !p.HasLeft(getToday()) {
+			current = append(current, p)
+		}
+	}
+	return current
+}
+
+// getOnboardingEvents returns events of first day of person, reminders before it and milestones after it
+func getOnboardingEvents(p config.Person, c *config.Config) []PersonalEvent {
+	if !c.Slack.Onboarding.Enabled {
+		return nil
+	}
+	today := getToday()
+	firstDay := getLocalDate(p.JoinDate)
+
+	var events []PersonalEvent
+	if firstDay.Equal(today) {
+		events = append(events, PersonalEvent{Type: FirstDay, Person: p})
+	}
+	for _, r := range getDueOneOffPreReminders(firstDay, c.Slack.Onboarding.PreStartReminders, c.Calendar) {
+		r := r
+		events = append(events, PersonalEvent{Type: UpcomingFirstDay, Person: p, PreReminder: &r})
+	}
+	for _, m := range c.Slack.Onboarding.Milestones {
+		m := m
+		if firstDay.AddDate(0, 0, m.DaysAfter).Equal(today) {
+			events = append(events, PersonalEvent{Type: OnboardingMilestone, Person: p, Milestone: &m})
+		}
+	}
+	return events
+}
+
 func DayAndMonthMatch(t time.Time) bool {
 	ct := GetNow()
 	return ct.Day() == t.Day() && ct.Month() == t.Month()
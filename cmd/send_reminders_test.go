@@ -1,14 +1,19 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/nomysz/celebrations/config"
+	"github.com/nomysz/celebrations/metrics"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -110,12 +115,16 @@ func getTestConfig() *config.Config {
 }
 
 type TestSlackClient struct {
-	botToken  string
-	userToken string
-	messages  []string
+	botToken       string
+	userToken      string
+	messages       []string
+	failingChannel string
 }
 
 func (sc *TestSlackClient) SendChannelMessage(channel string, msg string) error {
+	if channel == sc.failingChannel {
+		return errors.New("channel_not_found")
+	}
 	sc.messages = append(
 		sc.messages,
 		fmt.Sprintf("SENDING '%s' TO CHANNEL '%s' USING TOKEN %s", msg, channel, sc.botToken),
@@ -152,11 +161,12 @@ func TestSendReminders(t *testing.T) {
 		messages:  []string{},
 	}
 
-	SendReminders(
+	err := SendReminders(
 		getTestConfig(),
 		&sc,
 	)
 
+	assert.NoError(t, err)
 	assert.NotEmpty(t, sc.messages)
 
 	assert.Contains(t, sc.messages,
@@ -200,3 +210,763 @@ func TestSendReminders(t *testing.T) {
 		"SENDING 'Birthdays:\n1 June, <@birthday-slack-id> 22 years old\n11 June, <@monthly-report-birthday-slack-id> 30 years old\n\nAnniversaries:\n1 June, <@anniversary-slack-id> 2 years in company\n5 June, <@birthday-slack-id> 5 years in company\n21 June, <@monthly-report-anniversary-slack-id> 1 year in company\n' TO CHANNEL 'leaders' USING TOKEN bot-token",
 		"Error in monthly report")
 }
+
+func TestSendRemindersRespectsPrivacy(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	c := getTestConfig()
+	c.People[0].Privacy.DirectMessagesOnly = true
+	c.People[1].Privacy.NoPublicPosts = true
+	c.People[2].Privacy.HideAge = true
+	c.People[3].Privacy.OptOut = true
+
+	sc := TestSlackClient{
+		botToken:  c.Slack.BotToken,
+		userToken: c.Slack.UserToken,
+		messages:  []string{},
+	}
+
+	SendReminders(c, &sc)
+
+	assert.NotContains(t, sc.messages,
+		"SENDING '<@birthday-slack-id> is having birthday!' TO CHANNEL 'leaders' USING TOKEN bot-token",
+		"Channel post sent despite DM only preference")
+	assert.Contains(t, sc.messages,
+		"SENDING DM '<@birthday-slack-id> is having birthday!' TO 'leader-slack-id' USING TOKEN bot-token",
+		"DM not sent despite DM only preference")
+	assert.Contains(t, sc.messages,
+		"SETTING REMINDER '<@birthday-slack-id> is having birthday!' AT 'leader-slack-id' TO '15pm' USING TOKEN user-token",
+		"Personal reminder not set despite DM only preference")
+
+	assert.False(t, partialContains(sc.messages, "Happy anniversary <@anniversary-slack-id>"),
+		"Public post sent despite no public posts preference")
+
+	assert.Contains(t, sc.messages,
+		"SENDING 'Birthdays:\n11 June, <@monthly-report-birthday-slack-id>\n\nAnniversaries:\n1 June, <@anniversary-slack-id> 2 years in company\n' TO CHANNEL 'leaders' USING TOKEN bot-token",
+		"Error in monthly report privacy filtering")
+}
+
+func TestSendRemindersWithPersonTemplates(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	c := getTestConfig()
+	c.People[0].DisplayName = "John"
+	c.People[0].Email = "john@company.com"
+	c.People[1].RealName = "Mary Smith"
+	c.Slack.BirthdaysChannelReminder.MessageTemplate = "{{.Name}} ({{.Email}}) is having birthday!"
+	c.Slack.AnniversaryChannelReminder.MessageTemplate = "Happy anniversary {{.Mention}} aka {{.Name}}! %[2]s in Company!"
+	c.Slack.MonthlyReport.BirthdayLineTemplate = "{{.Date}}: {{.Name}}, {{.Years}}"
+	c.Slack.MonthlyReport.AnniversaryLineTemplate = "{{.Date}}: {{.Name}}, {{.Years}}"
+	c.People = c.People[:2]
+
+	sc := TestSlackClient{
+		botToken:  c.Slack.BotToken,
+		userToken: c.Slack.UserToken,
+		messages:  []string{},
+	}
+
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Contains(t, sc.messages,
+		"SENDING 'John (john@company.com) is having birthday!' TO CHANNEL 'leaders' USING TOKEN bot-token",
+		"Error in birthday channel msg template")
+	assert.Contains(t, sc.messages,
+		"SENDING 'Happy anniversary <@anniversary-slack-id> aka Mary Smith! 2 years in Company!' TO CHANNEL 'celebrations' USING TOKEN bot-token",
+		"Error in anniversary channel msg template")
+	assert.Contains(t, sc.messages,
+		"SENDING 'Birthdays:\n1 June: John, 22\n\nAnniversaries:\n1 June: Mary Smith, 2\n5 June: John, 5\n' TO CHANNEL 'leaders' USING TOKEN bot-token",
+		"Error in monthly report line templates")
+
+	c.Slack.BirthdaysChannelReminder.MessageTemplate = "{{.Nickname}} is having birthday!"
+	assert.ErrorContains(t, SendReminders(c, &sc), BirthdayReminderChannelHandler)
+}
+
+func TestRenderTemplate(t *testing.T) {
+	data := TemplateData{Person: config.Person{SlackMemberID: "ID01", DisplayName: "{{.Email}} 100%", Email: "john@company.com"}}
+
+	msg, err := renderTemplate("{{.Name}} ({{.Email}}) is having birthday!", data)
+	assert.NoError(t, err)
+	assert.Equal(t, "{{.Email}} 100% (john@company.com) is having birthday!", msg, "Data rendered as template")
+
+	msg, err = renderTemplate("<@%s> aka {{.Name}} is having birthday in %d days!", data, "ID01", 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "<@ID01> aka {{.Email}} 100% is having birthday in 7 days!", msg, "Data formatted as printf template")
+
+	msg, err = renderTemplate("<@%s> is having birthday!", data, "{{.Email}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "<@{{.Email}}> is having birthday!", msg, "Args rendered as template")
+
+	_, err = renderTemplate("{{.Nickname}} is having birthday!", data)
+	assert.ErrorContains(t, err, "Error rendering message template")
+}
+
+func TestSendRemindersReturnsFailures(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	c := getTestConfig()
+
+	sc := TestSlackClient{
+		botToken:       c.Slack.BotToken,
+		userToken:      c.Slack.UserToken,
+		messages:       []string{},
+		failingChannel: "leaders",
+	}
+
+	err := SendReminders(c, &sc)
+
+	assert.ErrorContains(t, err, MonthlyReportHandler)
+	assert.ErrorContains(t, err, BirthdayReminderChannelHandler)
+	assert.NotContains(t, err.Error(), AnniversaryChannelHandler)
+	assert.Contains(t, sc.messages,
+		"SENDING 'Happy anniversary <@anniversary-slack-id>! 2 years in Company!' TO CHANNEL 'celebrations' USING TOKEN bot-token",
+		"Failure of one handler stopped others")
+
+	var out strings.Builder
+	assert.NoError(t, metrics.Default.Write(&out))
+	assert.Contains(t, out.String(), `celebrations_failures_total{handler="monthly_report",backend="slack"}`)
+	assert.Contains(t, out.String(), `celebrations_messages_sent_total{handler="anniversary_channel_reminder",backend="slack"}`)
+	assert.Contains(t, out.String(), `celebrations_events_detected_total{event_type="monthly_report"}`)
+}
+
+func TestMessagesSentMetric(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	defaultRegistry := metrics.Default
+	defer func() { metrics.Default = defaultRegistry }()
+	metrics.Default = metrics.NewRegistry()
+
+	c := getTestConfig()
+	c.Slack.MonthlyReport.Enabled = false
+	c.Slack.BirthdaysChannelReminder.Enabled = false
+	c.Slack.BirthdaysPersonalReminder.Enabled = false
+	c.Slack.AnniversaryChannelReminder.Enabled = false
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	var out strings.Builder
+	assert.NoError(t, metrics.Default.Write(&out))
+	assert.Contains(t, out.String(),
+		fmt.Sprintf(`celebrations_messages_sent_total{handler="birthdays_direct_message_reminder",backend="slack"} %d`, len(sc.messages)),
+		"Messages sent not counted per message")
+	assert.Greater(t, len(sc.messages), 1)
+}
+
+func TestJSONLogging(t *testing.T) {
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, InitLogger(&buf, "info", "json"))
+	assert.Error(t, InitLogger(&buf, "verbose", "json"))
+	assert.Error(t, InitLogger(&buf, "info", "xml"))
+
+	c := getTestConfig()
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	var found bool
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(line), &entry), "Log line is not JSON")
+		if entry["msg"] == "Sent anniversary info to channel" {
+			found = true
+			assert.Equal(t, "anniversary", entry["event_type"])
+			assert.Equal(t, "anniversary-slack-id", entry["person_id"])
+			assert.Equal(t, "celebrations", entry["channel"])
+		}
+	}
+	assert.True(t, found, "Missing structured log of anniversary handler")
+}
+
+func TestReportScopes(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.December, 28, 10, 0, 0, 0, time.UTC)
+	}
+
+	lead := "lead"
+	c := getTestConfig()
+	c.People = []config.Person{
+		{SlackMemberID: "dec", BirthDate: time.Date(1990, time.December, 31, 0, 0, 0, 0, time.UTC), JoinDate: time.Date(2014, time.December, 2, 0, 0, 0, 0, time.UTC), LeadSlackMemberID: &lead},
+		{SlackMemberID: "jan", BirthDate: time.Date(1990, time.January, 3, 0, 0, 0, 0, time.UTC), JoinDate: time.Date(2015, time.January, 20, 0, 0, 0, 0, time.UTC), LeadSlackMemberID: &lead},
+	}
+
+	e := GetMonthlyReportEvent(c.People, config.MonthlyReport{})
+	assert.Len(t, e.Birthdays, 1)
+	assert.Len(t, e.Anniversaries, 1)
+	assert.Equal(t, "dec", e.Birthdays[0].SlackMemberID, "Error in current month scope")
+
+	e = GetMonthlyReportEvent(c.People, config.MonthlyReport{Scope: config.NextMonthScope})
+	if assert.Len(t, e.Birthdays, 1) && assert.Len(t, e.Anniversaries, 1) {
+		assert.Equal(t, "jan", e.Birthdays[0].SlackMemberID, "Error in next month scope")
+		assert.Equal(t, "jan", e.Anniversaries[0].SlackMemberID, "Error in next month scope")
+	}
+
+	e = GetMonthlyReportEvent(c.People, config.MonthlyReport{Scope: config.NextDaysScope, DaysAhead: 7})
+	assert.Len(t, e.Birthdays, 2, "Error in next days scope")
+	assert.Empty(t, e.Anniversaries, "Error in next days scope")
+
+	c.Slack.WeeklyDigest = config.WeeklyDigest{
+		Enabled:         true,
+		ChannelName:     "team",
+		MessageTemplate: "Birthdays:\n%s\nAnniversaries:\n%s",
+		Weekday:         "wednesday",
+	}
+	c.Slack.MonthlyReport.DayOfMonth = 28
+	c.Slack.MonthlyReport.Scope = config.NextMonthScope
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Contains(t, sc.messages,
+		"SENDING 'Birthdays:\n31 December, <@dec> 26 years old\n3 January, <@jan> 27 years old\n\nAnniversaries:\n' TO CHANNEL 'team' USING TOKEN ",
+		"Error in weekly digest sorting across year end")
+	assert.Contains(t, sc.messages,
+		"SENDING 'Birthdays:\n3 January, <@jan> 27 years old\n\nAnniversaries:\n20 January, <@jan> 2 years in company\n' TO CHANNEL 'leaders' USING TOKEN ",
+		"Error in monthly report on configured day")
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.December, 1, 10, 0, 0, 0, time.UTC)
+	}
+	sc = TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+	assert.Empty(t, sc.messages, "Reports sent on not configured days")
+}
+
+func TestLeadDigest(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	c := getTestConfig()
+	c.Slack.LeadDigest = config.LeadDigest{
+		Enabled:         true,
+		Weekday:         "wednesday",
+		DaysAhead:       7,
+		MessageTemplate: "Your team:\n%s\n%s",
+	}
+	c.People[0].Privacy.DirectMessagesOnly = true
+
+	events := GetLeadDigestEvents(c.People, c, NewOrgChart(c.People))
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "leader-slack-id", events[0].LeadSlackMemberID)
+		assert.Equal(t, "leader-always-informed-slack-id", events[1].LeadSlackMemberID)
+	}
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Contains(t, sc.messages,
+		"SENDING DM 'Your team:\n1 June, <@birthday-slack-id> 22 years old\n\n5 June, <@birthday-slack-id> 5 years in company\n' TO 'leader-slack-id' USING TOKEN ",
+		"Error in lead digest")
+	assert.Contains(t, sc.messages,
+		"SENDING DM 'Your team:\n\n1 June, <@anniversary-slack-id> 2 years in company\n' TO 'leader-always-informed-slack-id' USING TOKEN ",
+		"Error in lead digest")
+
+	c.Slack.FallbackRecipients = config.FallbackRecipients{
+		DeactivatedSlackMemberIDs: []string{"leader-slack-id"},
+		DefaultSlackMemberID:      "hr-slack-id",
+	}
+	c.People[1].LeadSlackMemberID = nil
+	events = GetLeadDigestEvents(c.People, c, NewOrgChart(c.People))
+	if assert.Len(t, events, 1, "Digest keyed on deactivated or missing lead") {
+		assert.Equal(t, "hr-slack-id", events[0].LeadSlackMemberID)
+		assert.Equal(t, []string{
+			"Deactivated lead leader-slack-id of birthday-slack-id",
+			"Missing lead of anniversary-slack-id",
+		}, getLeadDigestWarnings(events[0], c, NewOrgChart(c.People)))
+	}
+	c.Slack.FallbackRecipients = config.FallbackRecipients{}
+
+	c.People = c.People[2:]
+	sc = TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+	assert.False(t, partialContains(sc.messages, "Your team"), "Lead digest sent without celebrations")
+}
+
+func TestManagementChainNotifications(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	cto, vp, manager, person := "cto", "vp", "manager", "person"
+	c := getTestConfig()
+	c.Slack.BirthdaysDirectMessageReminder.AlwaysNotifySlackIds = []string{vp, "hr"}
+	c.People = []config.Person{
+		{SlackMemberID: person, BirthDate: getOffsetNowDate(-30, 0, 0), JoinDate: getOffsetNowDate(-1, 0, 5), LeadSlackMemberID: &manager},
+		{SlackMemberID: manager, BirthDate: getOffsetNowDate(-30, 0, 5), JoinDate: getOffsetNowDate(-1, 0, 5), LeadSlackMemberID: &vp},
+		{SlackMemberID: vp, BirthDate: getOffsetNowDate(-30, 0, 5), JoinDate: getOffsetNowDate(-1, 0, 5), LeadSlackMemberID: &cto},
+		{SlackMemberID: cto, BirthDate: getOffsetNowDate(-30, 0, 5), JoinDate: getOffsetNowDate(-1, 0, 5), LeadSlackMemberID: &person},
+	}
+
+	assert.Equal(t, []string{manager}, getManagementChain(c.People[0], c, NewOrgChart(c.People), 0))
+	assert.Equal(t, []string{manager, vp}, getManagementChain(c.People[0], c, NewOrgChart(c.People), 2))
+	assert.Equal(t, []string{manager, vp, cto}, getManagementChain(c.People[0], c, NewOrgChart(c.People), 10),
+		"Management chain loop not detected")
+
+	c.Slack.BirthdaysDirectMessageReminder.NotifyManagementChainDepth = 3
+	assert.Equal(t, []string{manager, vp, cto, "hr"}, getDirectMessageRecipients(c.People[0], c, NewOrgChart(c.People), 3, c.Slack.BirthdaysDirectMessageReminder.AlwaysNotifySlackIds),
+		"Recipients not deduplicated")
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	var dms []string
+	for _, m := range sc.messages {
+		if strings.HasPrefix(m, "SENDING DM '<@person> is having birthday!'") {
+			dms = append(dms, m)
+		}
+	}
+	assert.Len(t, dms, 4, "Each of management chain and always notified should get single DM")
+}
+
+func TestFallbackRecipients(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	vp, leaver, empty := "vp", "leaver", ""
+	c := getTestConfig()
+	c.Slack.BirthdaysDirectMessageReminder.AlwaysNotifySlackIds = nil
+	c.Slack.FallbackRecipients = config.FallbackRecipients{
+		DefaultSlackMemberID:      "default",
+		TeamHRPartners:            map[string]string{"platform": "hr-platform"},
+		DeactivatedSlackMemberIDs: []string{leaver},
+	}
+	birthDate := getOffsetNowDate(-30, 0, 0)
+	joinDate := getOffsetNowDate(-1, 0, 5)
+	c.People = []config.Person{
+		{SlackMemberID: "deactivated-lead", BirthDate: birthDate, JoinDate: joinDate, LeadSlackMemberID: &leaver},
+		{SlackMemberID: leaver, BirthDate: joinDate, JoinDate: joinDate, LeadSlackMemberID: &vp},
+		{SlackMemberID: "no-lead-in-team", BirthDate: birthDate, JoinDate: joinDate, Team: "platform"},
+		{SlackMemberID: "no-lead", BirthDate: birthDate, JoinDate: joinDate, LeadSlackMemberID: &empty},
+	}
+
+	lead, warning := getLeadOrFallback(c.People[0], c, NewOrgChart(c.People))
+	assert.Equal(t, vp, lead, "Lead's lead not used as fallback")
+	assert.Equal(t, "Deactivated lead leaver of deactivated-lead", warning)
+
+	lead, warning = getLeadOrFallback(c.People[2], c, NewOrgChart(c.People))
+	assert.Equal(t, "hr-platform", lead, "HR partner not used as fallback")
+	assert.Equal(t, "Missing lead of no-lead-in-team", warning)
+
+	lead, _ = getLeadOrFallback(c.People[3], c, NewOrgChart(c.People))
+	assert.Equal(t, "default", lead, "Default recipient not used as fallback")
+
+	lead, warning = getLeadOrFallback(c.People[1], c, NewOrgChart(c.People))
+	assert.Equal(t, vp, lead)
+	assert.Empty(t, warning)
+
+	defaultLogger := slog.Default()
+	defer slog.SetDefault(defaultLogger)
+	var buf bytes.Buffer
+	assert.NoError(t, InitLogger(&buf, "warn", "text"))
+
+	sc := TestSlackClient{messages: []string{}}
+	summary, err := sendReminders(c, &sc)
+	assert.NoError(t, err)
+	assert.NoError(t, summary.Err())
+	summary.Log()
+
+	assert.Equal(t, 1, strings.Count(buf.String(), "Missing lead of no-lead-in-team"), "Warning not logged once")
+	assert.Contains(t, buf.String(), "person_id=no-lead-in-team", "Warning logged without person")
+
+	assert.Contains(t, sc.messages,
+		"SENDING DM '<@deactivated-lead> is having birthday!' TO 'vp' USING TOKEN ")
+	assert.Contains(t, sc.messages,
+		"SETTING REMINDER '<@no-lead-in-team> is having birthday!' AT 'hr-platform' TO '15pm' USING TOKEN ")
+	assert.Contains(t, sc.messages,
+		"SENDING DM '<@no-lead> is having birthday!' TO 'default' USING TOKEN ")
+
+	assert.ElementsMatch(t, []string{
+		"Deactivated lead leaver of deactivated-lead",
+		"Missing lead of no-lead-in-team",
+		"Missing lead of no-lead",
+	}, summary.Warnings(), "Fallback warnings not recorded")
+}
+
+func TestMultiplePreReminders(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	lead := "lead"
+	c := getTestConfig()
+	c.Slack.MonthlyReport.Enabled = false
+	c.Slack.BirthdaysDirectMessageReminder.AlwaysNotifySlackIds = nil
+	c.Slack.BirthdaysDirectMessageReminder.PreRemidnerMessageTemplate = "<@%s> is having birthday in %d days!"
+	c.Slack.BirthdaysDirectMessageReminder.PreReminders = []config.PreReminder{
+		{DaysBefore: 14, MessageTemplate: "Order a cake for {{.Name}}, birthday in {{.DaysBefore}} days!"},
+		{DaysBefore: 2, MessageTemplate: "<@%s> is having birthday in %d days!"},
+	}
+	c.People = []config.Person{
+		{SlackMemberID: "in-14-days", DisplayName: "John", BirthDate: getOffsetNowDate(-30, 0, 14), JoinDate: getOffsetNowDate(-1, 1, 0), LeadSlackMemberID: &lead},
+		{SlackMemberID: "in-3-days", BirthDate: getOffsetNowDate(-30, 0, 3), JoinDate: getOffsetNowDate(-1, 1, 0), LeadSlackMemberID: &lead},
+		{SlackMemberID: "in-2-days", BirthDate: getOffsetNowDate(-30, 0, 2), JoinDate: getOffsetNowDate(-1, 1, 0), LeadSlackMemberID: &lead},
+	}
+
+	assert.Len(t, c.Slack.BirthdaysDirectMessageReminder.GetPreReminders(), 3, "Legacy pre-reminder not kept")
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Equal(t, []string{
+		"SENDING DM 'Order a cake for John, birthday in 14 days!' TO 'lead' USING TOKEN ",
+		"SENDING DM '<@in-3-days> is having birthday in 3 days!' TO 'lead' USING TOKEN ",
+		"SENDING DM '<@in-2-days> is having birthday in 2 days!' TO 'lead' USING TOKEN ",
+	}, sc.messages)
+}
+
+func TestAnniversaryPreReminders(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	lead := "lead"
+	c := getTestConfig()
+	c.Slack.MonthlyReport.Enabled = false
+	c.Slack.AnniversaryChannelReminder.Enabled = false
+	c.Slack.AnniversariesDirectMessageReminder = config.AnniversariesDirectMessageReminder{
+		Enabled:         true,
+		MessageTemplate: "<@%s> has %s in company today!",
+		PreReminders: []config.PreReminder{
+			{DaysBefore: 7, MessageTemplate: "<@%s> will have %s in company in %d days!"},
+		},
+		AlwaysNotifySlackIds: []string{"hr"},
+	}
+	c.People = []config.Person{
+		{SlackMemberID: "in-7-days", BirthDate: getOffsetNowDate(-30, 1, 0), JoinDate: getOffsetNowDate(-3, 0, 7), LeadSlackMemberID: &lead},
+		{SlackMemberID: "today", BirthDate: getOffsetNowDate(-30, 1, 0), JoinDate: getOffsetNowDate(-1, 0, 0), LeadSlackMemberID: &lead},
+		{SlackMemberID: "not-joined-yet", BirthDate: getOffsetNowDate(-30, 1, 0), JoinDate: getOffsetNowDate(0, 0, 7), LeadSlackMemberID: &lead},
+	}
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Equal(t, []string{
+		"SENDING DM '<@in-7-days> will have 3 years in company in 7 days!' TO 'lead' USING TOKEN ",
+		"SENDING DM '<@in-7-days> will have 3 years in company in 7 days!' TO 'hr' USING TOKEN ",
+		"SENDING DM '<@today> has 1 year in company today!' TO 'lead' USING TOKEN ",
+		"SENDING DM '<@today> has 1 year in company today!' TO 'hr' USING TOKEN ",
+	}, sc.messages)
+}
+
+func TestBusinessDaysPreReminders(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	// Friday
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 3, 10, 0, 0, 0, time.UTC)
+	}
+
+	lead := "lead"
+	c := getTestConfig()
+	c.Slack.BirthdaysDirectMessageReminder.PreReminderDaysBefore = 0
+	c.Slack.BirthdaysDirectMessageReminder.AlwaysNotifySlackIds = nil
+	c.Slack.BirthdaysDirectMessageReminder.PreReminders = []config.PreReminder{
+		{DaysBefore: 1, Unit: config.BusinessDaysUnit, MessageTemplate: "<@%s> is having birthday after %d business day!"},
+	}
+	c.Calendar.Holidays = []time.Time{time.Date(2016, time.June, 6, 0, 0, 0, 0, time.UTC)}
+	c.People = []config.Person{
+		// Tuesday after Monday holiday
+		{SlackMemberID: "tuesday", BirthDate: time.Date(1990, time.June, 7, 0, 0, 0, 0, time.UTC), JoinDate: getOffsetNowDate(-1, 1, 0), LeadSlackMemberID: &lead},
+		// Sunday
+		{SlackMemberID: "sunday", BirthDate: time.Date(1990, time.June, 5, 0, 0, 0, 0, time.UTC), JoinDate: getOffsetNowDate(-1, 1, 0), LeadSlackMemberID: &lead},
+		// Wednesday
+		{SlackMemberID: "wednesday", BirthDate: time.Date(1990, time.June, 8, 0, 0, 0, 0, time.UTC), JoinDate: getOffsetNowDate(-1, 1, 0), LeadSlackMemberID: &lead},
+	}
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Equal(t, []string{
+		"SENDING DM '<@tuesday> is having birthday after 1 business day!' TO 'lead' USING TOKEN ",
+		"SENDING DM '<@sunday> is having birthday after 1 business day!' TO 'lead' USING TOKEN ",
+	}, sc.messages)
+}
+
+func TestCustomEvents(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	lead := "lead"
+	c := getTestConfig()
+	c.Slack.BirthdaysDirectMessageReminder.Enabled = false
+	c.Slack.MonthlyReport.MessageTemplate = "Birthdays:\n%s\nAnniversaries:\n%s\nName days:\n{{.CustomEvents.name_day}}\nDisabled:\n{{.CustomEvents.disabled}}\nFooter"
+	c.Slack.CustomEvents = []config.CustomEvent{
+		{
+			Enabled:            true,
+			Name:               "name_day",
+			DateField:          "name_day",
+			ChannelName:        "celebrations",
+			MessageTemplate:    "Happy name day <@%s>!",
+			PreReminders:       []config.PreReminder{{DaysBefore: 3, MessageTemplate: "<@%s> has name day in %d days!"}},
+			ReportLineTemplate: "{{.Date}}, {{.Mention}}",
+		},
+		{
+			Enabled:         false,
+			Name:            "disabled",
+			DateField:       "name_day",
+			ChannelName:     "celebrations",
+			MessageTemplate: "Disabled <@%s>!",
+		},
+	}
+	c.People = []config.Person{
+		{SlackMemberID: "today", BirthDate: getOffsetNowDate(-30, 2, 0), JoinDate: getOffsetNowDate(-1, 2, 0), LeadSlackMemberID: &lead,
+			Dates: map[string]time.Time{"name_day": getOffsetNowDate(-10, 0, 0)}},
+		{SlackMemberID: "in-3-days", BirthDate: getOffsetNowDate(-30, 2, 0), JoinDate: getOffsetNowDate(-1, 2, 0), LeadSlackMemberID: &lead,
+			Dates: map[string]time.Time{"name_day": getOffsetNowDate(-10, 0, 3)}},
+		{SlackMemberID: "no-dates", BirthDate: getOffsetNowDate(-30, 2, 0), JoinDate: getOffsetNowDate(-1, 2, 0), LeadSlackMemberID: &lead},
+	}
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Equal(t, []string{
+		"SENDING 'Happy name day <@today>!' TO CHANNEL 'celebrations' USING TOKEN ",
+		"SENDING DM '<@in-3-days> has name day in 3 days!' TO 'lead' USING TOKEN ",
+		"SENDING 'Birthdays:\n\nAnniversaries:\n\nName days:\n1 June, <@today>\n4 June, <@in-3-days>\n\nDisabled:\n\nFooter' TO CHANNEL 'leaders' USING TOKEN ",
+	}, sc.messages)
+}
+
+func TestCompanyEvents(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	c := getTestConfig()
+	c.People = nil
+	c.Slack.MonthlyReport.MessageTemplate = "Birthdays:\n%s\nAnniversaries:\n%s\nCompany events:\n{{.CompanyEvents}}\nFooter"
+	c.Slack.CompanyEvents = []config.CompanyEvent{
+		{Enabled: true, Name: "Company anniversary", Date: time.Date(2010, time.June, 20, 0, 0, 0, 0, time.UTC), Yearly: true,
+			ChannelName: "general", MessageTemplate: "{{.EventName}}: {{.Years}} years!"},
+		{Enabled: true, Name: "Office opening", Date: time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC),
+			ChannelName: "general", MessageTemplate: "%s today!"},
+		{Enabled: true, Name: "Last year launch", Date: time.Date(2015, time.June, 10, 0, 0, 0, 0, time.UTC),
+			ChannelName: "general", MessageTemplate: "%s today!"},
+		{Enabled: false, Name: "Disabled", Date: time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC),
+			ChannelName: "general", MessageTemplate: "%s today!"},
+	}
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Equal(t, []string{
+		"SENDING 'Birthdays:\n\nAnniversaries:\n\nCompany events:\n1 June, Office opening\n20 June, Company anniversary (6 years)\n\nFooter' TO CHANNEL 'leaders' USING TOKEN ",
+		"SENDING 'Office opening today!' TO CHANNEL 'general' USING TOKEN ",
+	}, sc.messages)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 20, 10, 0, 0, 0, time.UTC)
+	}
+	sc = TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+	assert.Equal(t, []string{"SENDING 'Company anniversary: 6 years!' TO CHANNEL 'general' USING TOKEN "}, sc.messages)
+}
+
+func TestOnboarding(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	c := getTestConfig()
+	c.Slack.MonthlyReport.Enabled = false
+	c.Slack.AnniversaryChannelReminder.Enabled = true
+	c.Slack.Onboarding = config.Onboarding{
+		Enabled:                true,
+		WelcomeChannelName:     "general",
+		WelcomeMessageTemplate: "Welcome <@%s>!",
+		PreStartReminders: []config.PreReminder{
+			{DaysBefore: 7, MessageTemplate: "<@%s> starts in %d days"},
+		},
+		Milestones: []config.Milestone{
+			{DaysAfter: 30, MessageTemplate: "<@%s> is with us for %d days"},
+			{DaysAfter: 90, MessageTemplate: "Probation of <@%s> ends today (%d days)"},
+		},
+	}
+	lead := "lead-slack-id"
+	c.People = []config.Person{
+		{SlackMemberID: "first-day-slack-id", BirthDate: getOffsetNowDate(-30, 2, 0), JoinDate: getOffsetNowDate(0, 0, 0)},
+		{SlackMemberID: "starting-slack-id", BirthDate: getOffsetNowDate(-30, 2, 0), JoinDate: getOffsetNowDate(0, 0, 7), LeadSlackMemberID: &lead},
+		{SlackMemberID: "starting-next-year-slack-id", BirthDate: getOffsetNowDate(-30, 2, 0), JoinDate: getOffsetNowDate(1, 0, 7), LeadSlackMemberID: &lead},
+		{SlackMemberID: "month-slack-id", BirthDate: getOffsetNowDate(-30, 2, 0), JoinDate: getOffsetNowDate(0, 0, -30), LeadSlackMemberID: &lead},
+		{SlackMemberID: "probation-slack-id", BirthDate: getOffsetNowDate(-30, 2, 0), JoinDate: getOffsetNowDate(0, 0, -90), LeadSlackMemberID: &lead},
+	}
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.ElementsMatch(t, []string{
+		"SENDING 'Welcome <@first-day-slack-id>!' TO CHANNEL 'general' USING TOKEN ",
+		"SENDING DM '<@starting-slack-id> starts in 7 days' TO 'lead-slack-id' USING TOKEN ",
+		"SENDING DM '<@month-slack-id> is with us for 30 days' TO 'lead-slack-id' USING TOKEN ",
+		"SENDING DM 'Probation of <@probation-slack-id> ends today (90 days)' TO 'lead-slack-id' USING TOKEN ",
+	}, sc.messages)
+
+	c.People = c.People[:1]
+	c.People[0].Privacy.NoPublicPosts = true
+	sc = TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+	assert.Empty(t, sc.messages, "Welcome posted despite no public posts preference")
+
+	c.People[0].Privacy.NoPublicPosts = false
+	c.Slack.Onboarding.Enabled = false
+	sc = TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+	assert.Equal(t, []string{
+		"SENDING 'Happy anniversary <@first-day-slack-id>! 1 year in Company!' TO CHANNEL 'celebrations' USING TOKEN ",
+	}, sc.messages, "First day anniversary changed with onboarding disabled")
+
+	// People yet to start are only onboarded, even when celebrating today or later this month
+	c.Slack.Onboarding.Enabled = true
+	c.Slack.MonthlyReport.Enabled = true
+	c.People = []config.Person{
+		{SlackMemberID: "starting-slack-id", BirthDate: getOffsetNowDate(-30, 0, 0), JoinDate: getOffsetNowDate(0, 0, 7), LeadSlackMemberID: &lead},
+		{SlackMemberID: "starting-this-month-slack-id", BirthDate: getOffsetNowDate(-30, 0, 10), JoinDate: getOffsetNowDate(0, 0, 19), LeadSlackMemberID: &lead},
+	}
+	sc = TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+	assert.Equal(t, []string{
+		"SENDING DM '<@starting-slack-id> starts in 7 days' TO 'lead-slack-id' USING TOKEN ",
+		"SENDING 'Birthdays:\n\nAnniversaries:\n' TO CHANNEL 'leaders' USING TOKEN ",
+	}, sc.messages, "People yet to start celebrated")
+}
+
+func TestFarewell(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	c := getTestConfig()
+	c.Slack.FarewellReminder = config.FarewellReminder{
+		Enabled:         true,
+		ChannelName:     "general",
+		MessageTemplate: "Goodbye <@%s>, thank you for {{.Years}} years!",
+		PreReminders: []config.PreReminder{
+			{DaysBefore: 7, MessageTemplate: "<@%s> is leaving in %d days"},
+		},
+	}
+	lead := "lead-slack-id"
+	leftLead := "left-lead-slack-id"
+	c.People = []config.Person{
+		{SlackMemberID: "leaving-today-slack-id", BirthDate: getOffsetNowDate(-30, 2, 0), JoinDate: getOffsetNowDate(-3, 2, 0),
+			LeaveDate: getOffsetNowDate(0, 0, 0), LeadSlackMemberID: &lead},
+		{SlackMemberID: "leaving-soon-slack-id", BirthDate: getOffsetNowDate(-30, 2, 0), JoinDate: getOffsetNowDate(-3, 2, 0),
+			LeaveDate: getOffsetNowDate(0, 0, 7), LeadSlackMemberID: &leftLead},
+		{SlackMemberID: leftLead, BirthDate: getOffsetNowDate(-30, 0, 0), JoinDate: getOffsetNowDate(-3, 0, 0),
+			LeaveDate: getOffsetNowDate(0, 0, -1), LeadSlackMemberID: &lead},
+	}
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Equal(t, []string{
+		"SENDING 'Goodbye <@leaving-today-slack-id>, thank you for 3 years!' TO CHANNEL 'general' USING TOKEN ",
+		"SENDING DM '<@leaving-soon-slack-id> is leaving in 7 days' TO 'lead-slack-id' USING TOKEN ",
+		"SENDING 'Birthdays:\n\nAnniversaries:\n' TO CHANNEL 'leaders' USING TOKEN ",
+	}, sc.messages, "Person who has left is celebrated or notified as lead")
+
+	c.People[0].Privacy.NoPublicPosts = true
+	sc = TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+	assert.False(t, partialContains(sc.messages, "Goodbye"), "Farewell posted despite no public posts preference")
+
+	c.Slack.LeadDigest = config.LeadDigest{Enabled: true, Weekday: "wednesday", DaysAhead: 7, MessageTemplate: "Your team:\n%s\n%s"}
+	c.People[1].BirthDate = getOffsetNowDate(-30, 0, 3)
+	events := GetLeadDigestEvents(getCurrentPeople(c.People), c, NewOrgChart(c.People))
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, lead, events[0].LeadSlackMemberID, "Lead digest keyed on lead who has left")
+	}
+}
+
+func TestGroupedChannelReminders(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 10, 0, 0, 0, time.UTC)
+	}
+
+	c := getTestConfig()
+	c.Slack.MonthlyReport.Enabled = false
+	c.Slack.BirthdaysDirectMessageReminder.Enabled = false
+	c.Slack.BirthdaysPersonalReminder.Enabled = false
+	c.Slack.AnniversaryChannelReminder = config.AnniversaryChannelReminder{
+		Enabled:                true,
+		ChannelName:            "celebrations",
+		MessageTemplate:        "Happy anniversary <@%s>! %s in company!",
+		GroupedMessageTemplate: "Happy anniversary!\n%s",
+		GroupedLineTemplate:    "<@%s> - %s",
+	}
+	c.Slack.BirthdaysChannelReminder = config.BirthdaysChannelReminder{
+		Enabled:                true,
+		ChannelName:            "leaders",
+		MessageTemplate:        "<@%s> is having birthday today!",
+		GroupedMessageTemplate: "Birthdays today:\n{{.Lines}}",
+		GroupedLineTemplate:    "{{.Name}}",
+	}
+	lead := "lead-slack-id"
+	c.People = []config.Person{
+		{SlackMemberID: "first-slack-id", DisplayName: "{{.Email}} 100%", BirthDate: getOffsetNowDate(-30, 0, 0), JoinDate: getOffsetNowDate(-2, 0, 0), LeadSlackMemberID: &lead},
+		{SlackMemberID: "second-slack-id", BirthDate: getOffsetNowDate(-40, 2, 0), JoinDate: getOffsetNowDate(-5, 0, 0), LeadSlackMemberID: &lead},
+		{SlackMemberID: "third-slack-id", BirthDate: getOffsetNowDate(-35, 0, 0), JoinDate: getOffsetNowDate(-1, 0, 0), LeadSlackMemberID: &lead,
+			Privacy: config.Privacy{NoPublicPosts: true}},
+	}
+
+	sc := TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Equal(t, []string{
+		"SENDING 'Happy anniversary!\n<@first-slack-id> - 2 years\n<@second-slack-id> - 5 years' TO CHANNEL 'celebrations' USING TOKEN ",
+		"SENDING 'Birthdays today:\n{{.Email}} 100%\n<@third-slack-id>' TO CHANNEL 'leaders' USING TOKEN ",
+	}, sc.messages)
+
+	c.People = c.People[1:]
+	sc = TestSlackClient{messages: []string{}}
+	assert.NoError(t, SendReminders(c, &sc))
+
+	assert.Equal(t, []string{
+		"SENDING 'Happy anniversary <@second-slack-id>! 5 years in company!' TO CHANNEL 'celebrations' USING TOKEN ",
+		"SENDING '<@third-slack-id> is having birthday today!' TO CHANNEL 'leaders' USING TOKEN ",
+	}, sc.messages, "Single person of the day not posted with regular template")
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nomysz/celebrations/config"
+	"github.com/nomysz/celebrations/preferences"
+	"github.com/nomysz/celebrations/slack"
+	"github.com/spf13/cobra"
+)
+
+const slashCommandPath = "/slack/commands"
+
+const slashCommandHelp = "Usage: `/celebrations [show|opt-out|opt-in|no-public-posts|public-posts|hide-age|show-age|help]`"
+
+func newServeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run long-lived server",
+		Long: fmt.Sprintf(
+			"Serve `/celebrations` Slack slash command on %s letting people manage their own privacy preferences",
+			slashCommandPath,
+		),
+		Run: func(cmd *cobra.Command, args []string) {
+			c, err := config.GetConfig()
+			if err != nil {
+				fatal("Invalid config", "error", err)
+			}
+
+			if c.Slack.SigningSecret == "" {
+				fatal("Missing required environment variable: SLACK_SIGNING_SECRET (required for serving slash command)")
+			}
+			if c.Server.ListenAddress == "" || c.Server.PreferencesFile == "" {
+				fatal("Missing required config attributes: server.listen_address and server.preferences_file")
+			}
+
+			store, err := preferences.Load(c.Server.PreferencesFile)
+			if err != nil {
+				fatal("Error loading preferences", "error", err)
+			}
+
+			mux := http.NewServeMux()
+			mux.Handle(slashCommandPath, SlashCommandHandler(c, store))
+
+			server := &http.Server{
+				Addr:              c.Server.ListenAddress,
+				Handler:           mux,
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+
+			slog.Info("Serving", "address", c.Server.ListenAddress)
+			fatal("Error serving", "error", server.ListenAndServe())
+		},
+	}
+}
+
+type slashCommandResponse struct {
+	ResponseType string `json:"response_type"`
+	Text         string `json:"text"`
+}
+
+func SlashCommandHandler(c *config.Config, store *preferences.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		sc, err := slack.ParseSlashCommand(r, c.Slack.SigningSecret)
+		if err != nil {
+			slog.Warn("Error handling slash command", "error", err)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		text, err := runSlashCommand(sc, c, store)
+		if err != nil {
+			slog.Error("Error handling slash command", "person_id", sc.UserID, "error", err)
+			text = "Something went wrong, your preferences were not saved. Please try again later."
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(slashCommandResponse{
+			ResponseType: "ephemeral",
+			Text:         text,
+		}); err != nil {
+			slog.Error("Error writing slash command response", "error", err)
+		}
+	}
+}
+
+func runSlashCommand(sc slack.SlashCommand, c *config.Config, store *preferences.Store) (string, error) {
+	privacy := getPrivacy(sc.UserID, c, store)
+
+	switch strings.TrimSpace(strings.ToLower(sc.Text)) {
+	case "", "show":
+		return getPreferencesText(sc.UserID, c, privacy), nil
+	case "opt-out":
+		privacy.OptOut = true
+	case "opt-in":
+		privacy.OptOut = false
+	case "no-public-posts":
+		privacy.NoPublicPosts = true
+	case "public-posts":
+		privacy.NoPublicPosts = false
+	case "hide-age":
+		privacy.HideAge = true
+	case "show-age":
+		privacy.HideAge = false
+	default:
+		return slashCommandHelp, nil
+	}
+
+	if err := store.Set(sc.UserID, privacy); err != nil {
+		return "", err
+	}
+	slog.Info("Updated privacy preferences", "person_id", sc.UserID)
+
+	return "Preferences saved.\n" + getPreferencesText(sc.UserID, c, privacy), nil
+}
+
+func getPrivacy(slackMemberID string, c *config.Config, store *preferences.Store) config.Privacy {
+	if p, ok := store.Get(slackMemberID); ok {
+		return p
+	}
+	for _, p := range c.People {
+		if p.SlackMemberID == slackMemberID {
+			return p.Privacy
+		}
+	}
+	return config.Privacy{}
+}
+
+func getPreferencesText(slackMemberID string, c *config.Config, p config.Privacy) string {
+	text := "No birth date or join date stored for you.\n"
+	for _, person := range c.People {
+		if person.SlackMemberID == slackMemberID {
+			text = fmt.Sprintf(
+				"Birth date: %s\nJoin date: %s\n",
+				person.BirthDate.Format("2 January"),
+				person.JoinDate.Format(time.DateOnly),
+			)
+			break
+		}
+	}
+	return text + fmt.Sprintf(
+		"Opted out: %t\nNo public posts: %t\nHide age: %t\nDirect messages only: %t\n%s",
+		p.OptOut,
+		p.NoPublicPosts,
+		p.HideAge,
+		p.DirectMessagesOnly,
+		slashCommandHelp,
+	)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nomysz/celebrations/preferences"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSigningSecret = "test-signing-secret"
+
+func getSignedSlashCommandRequest(userID string, text string, secret string) *http.Request {
+	body := url.Values{
+		"command": {"/celebrations"},
+		"user_id": {userID},
+		"text":    {text},
+	}.Encode()
+	timestamp := fmt.Sprint(time.Now().Unix())
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+
+	r := httptest.NewRequest(http.MethodPost, slashCommandPath, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	r.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return r
+}
+
+func TestSlashCommandHandler(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	GetNow = func() time.Time {
+		return time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	c := getTestConfig()
+	c.Slack.SigningSecret = testSigningSecret
+	c.Server.PreferencesFile = filepath.Join(t.TempDir(), "preferences.yml")
+
+	store, err := preferences.Load(c.Server.PreferencesFile)
+	assert.NoError(t, err)
+
+	handler := SlashCommandHandler(c, store)
+
+	w := httptest.NewRecorder()
+	handler(w, getSignedSlashCommandRequest("anniversary-slack-id", "opt-out", "invalid-secret"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "Request with invalid signature accepted")
+
+	w = httptest.NewRecorder()
+	handler(w, getSignedSlashCommandRequest("anniversary-slack-id", "show", testSigningSecret))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Join date: 2014-06-01")
+	assert.Contains(t, w.Body.String(), "Opted out: false")
+
+	w = httptest.NewRecorder()
+	handler(w, getSignedSlashCommandRequest("anniversary-slack-id", "opt-out", testSigningSecret))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Opted out: true")
+
+	persisted, err := preferences.Load(c.Server.PreferencesFile)
+	assert.NoError(t, err)
+	p, ok := persisted.Get("anniversary-slack-id")
+	assert.True(t, ok, "Preferences not persisted")
+	assert.True(t, p.OptOut, "Preferences not persisted")
+
+	handler(httptest.NewRecorder(), getSignedSlashCommandRequest("anniversary-slack-id", "hide-age", testSigningSecret))
+	w = httptest.NewRecorder()
+	handler(w, getSignedSlashCommandRequest("anniversary-slack-id", "opt-in", testSigningSecret))
+	assert.Contains(t, w.Body.String(), "Opted out: false")
+	assert.Contains(t, w.Body.String(), "Hide age: true", "Opting in reset other preferences")
+	handler(httptest.NewRecorder(), getSignedSlashCommandRequest("anniversary-slack-id", "opt-out", testSigningSecret))
+
+	sc := TestSlackClient{
+		botToken:  c.Slack.BotToken,
+		userToken: c.Slack.UserToken,
+		messages:  []string{},
+	}
+
+	SendReminders(c, &sc)
+
+	assert.NotEmpty(t, sc.messages)
+	assert.False(t, partialContains(sc.messages, "<@anniversary-slack-id>"),
+		"Stored opt out preference not respected")
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/nomysz/celebrations/metrics"
+	"github.com/nomysz/celebrations/slack"
+)
+
+// Backend used to deliver messages, reported in metrics
+const SlackBackend = "slack"
+
+// ErrSkipped is returned (wrapped) by handlers deliberately not sending anything
+var ErrSkipped = errors.New("skipped")
+
+func skip(format string, a ...any) error {
+	return fmt.Errorf("%w: %s", ErrSkipped, fmt.Sprintf(format, a...))
+}
+
+type HandlerResults struct {
+	Sent    int
+	Skipped int
+	Failed  int
+}
+
+// Summary collects results of all handlers executed during single run
+type Summary struct {
+	handlers []string
+	results  map[string]*HandlerResults
+	errs     []error
+	warnings []string
+	// Attributes of events warnings were recorded for, logged with summary
+	warningAttrs [][]any
+}
+
+func NewSummary() *Summary {
+	return &Summary{results: map[string]*HandlerResults{}}
+}
+
+func (s *Summary) Record(handler string, e Event, err error) {
+	r, ok := s.results[handler]
+	if !ok {
+		r = &HandlerResults{}
+		s.results[handler] = r
+		s.handlers = append(s.handlers, handler)
+	}
+
+	attrs := append([]any{"handler", handler}, getEventAttrs(e)...)
+
+	switch {
+	case err == nil:
+		r.Sent++
+	case errors.Is(err, ErrSkipped):
+		r.Skipped++
+		slog.Info("Skipped handler", append(attrs, "reason", err)...)
+	default:
+		r.Failed++
+		metrics.Default.Failure(handler, SlackBackend)
+		s.errs = append(s.errs, fmt.Errorf("%s: %w", handler, err))
+		slog.Error("Handler failed", append(attrs, "error", err)...)
+	}
+}
+
+func (s *Summary) Results(handler string) HandlerResults {
+	if r, ok := s.results[handler]; ok {
+		return *r
+	}
+	return HandlerResults{}
+}
+
+// Warn records problem not preventing sending, e.g. stale roster data, logged with summary
+func (s *Summary) Warn(e Event, warning string) {
+	s.warnings = append(s.warnings, warning)
+	s.warningAttrs = append(s.warningAttrs, getEventAttrs(e))
+}
+
+func (s *Summary) Warnings() []string {
+	return s.warnings
+}
+
+// Err returns all failures joined or nil when nothing failed
+func (s *Summary) Err() error {
+	return errors.Join(s.errs...)
+}
+
+func (s *Summary) Log() {
+	if len(s.warnings) > 0 {
+		slog.Warn("Summary", "warnings", len(s.warnings))
+	}
+	for i, w := range s.warnings {
+		slog.Warn("Summary", append([]any{"warning", w}, s.warningAttrs[i]...)...)
+	}
+	if len(s.handlers) == 0 {
+		slog.Info("Summary: nothing to send today")
+		return
+	}
+	for _, h := range s.handlers {
+		r := s.results[h]
+		slog.Info(
+			"Summary",
+			"handler", h,
+			"sent", r.Sent,
+			"skipped", r.Skipped,
+			"failed", r.Failed,
+		)
+	}
+}
+
+// sentCounter counts messages successfully sent by handler, which may send any number of them
+type sentCounter struct {
+	slack.SlackCommunicator
+	handler string
+}
+
+func countSent(handler string, sc slack.SlackCommunicator) slack.SlackCommunicator {
+	return sentCounter{SlackCommunicator: sc, handler: handler}
+}
+
+func (s sentCounter) count(err error) error {
+	if err == nil {
+		metrics.Default.MessageSent(s.handler, SlackBackend)
+	}
+	return err
+}
+
+func (s sentCounter) SendChannelMessage(channel string, msg string) error {
+	return s.count(s.SlackCommunicator.SendChannelMessage(channel, msg))
+}
+
+func (s sentCounter) SendDirectMessage(slackId string, msg string) error {
+	return s.count(s.SlackCommunicator.SendDirectMessage(slackId, msg))
+}
+
+func (s sentCounter) SetPersonalReminder(slackId string, time string, msg string) error {
+	return s.count(s.SlackCommunicator.SetPersonalReminder(slackId, time, msg))
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/nomysz/celebrations/config"
+)
+
+// TemplateData is passed to message templates, e.g. `{{.Name}}`, `{{.Mention}}`, `{{.RealName}}` or `{{.Email}}`
+type TemplateData struct {
+	config.Person
+	// Date of the event formatted as e.g. 2 January (monthly report only)
+	Date string
+	// Age or years in company, 0 when person hides age (monthly report only)
+	Years int
+	// Days left to the event (pre-reminders only)
+	DaysBefore int
+	// Name of the event (company events only)
+	EventName string
+	// Lines of company events within report period (reports only)
+	CompanyEvents string
+	// Lines of people celebrating within report period by custom event name (reports only)
+	CustomEvents map[string]string
+	// Rendered lines of everyone celebrating, one per line (grouped channel posts only)
+	Lines string
+}
+
+// renderTemplate executes text/template with data and formats result as printf-style template with
+// args (for backward compatibility), values of data and args are never parsed as templates
+func renderTemplate(tmpl string, data TemplateData, args ...any) (string, error) {
+	printf := strings.Contains(tmpl, "%")
+	msg := tmpl
+	if strings.Contains(tmpl, "{{") {
+		if printf {
+			data = escapePercent(data)
+		}
+		t, err := template.New("message").Option("missingkey=error").Parse(tmpl)
+		if err != nil {
+			return "", fmt.Errorf("Invalid message template: %w", err)
+		}
+		var b strings.Builder
+		if err := t.Execute(&b, data); err != nil {
+			return "", fmt.Errorf("Error rendering message template: %w", err)
+		}
+		msg = b.String()
+	}
+	if printf {
+		msg = fmt.Sprintf(msg, args...)
+	}
+	return msg, nil
+}
+
+// escapePercent escapes text fields of data so that rendering them does not add printf verbs
+func escapePercent(data TemplateData) TemplateData {
+	escape := func(s string) string {
+		return strings.ReplaceAll(s, "%", "%%")
+	}
+	data.SlackMemberID = escape(data.SlackMemberID)
+	data.DisplayName = escape(data.DisplayName)
+	data.RealName = escape(data.RealName)
+	data.Email = escape(data.Email)
+	data.Team = escape(data.Team)
+	data.Date = escape(data.Date)
+	data.EventName = escape(data.EventName)
+	data.Lines = escape(data.Lines)
+	data.CompanyEvents = escape(data.CompanyEvents)
+	if data.CustomEvents != nil {
+		customEvents := map[string]string{}
+		for name, text := range data.CustomEvents {
+			customEvents[name] = escape(text)
+		}
+		data.CustomEvents = customEvents
+	}
+	return data
+}
@@ -1,7 +1,9 @@
 package config
 
 import (
-	"log"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -9,34 +11,192 @@ import (
 	"github.com/spf13/viper"
 )
 
+type Privacy struct {
+	OptOut             bool `mapstructure:"opt_out" yaml:"opt_out"`
+	NoPublicPosts      bool `mapstructure:"no_public_posts" yaml:"no_public_posts"`
+	HideAge            bool `mapstructure:"hide_age" yaml:"hide_age"`
+	DirectMessagesOnly bool `mapstructure:"direct_messages_only" yaml:"direct_messages_only"`
+}
+
 type Person struct {
-	SlackMemberID     string    `mapstructure:"slack_member_id" validate:"required"`
-	BirthDate         time.Time `mapstructure:"birth_date" validate:"required"`
-	JoinDate          time.Time `mapstructure:"join_date" validate:"required"`
+	SlackMemberID string    `mapstructure:"slack_member_id" validate:"required"`
+	BirthDate     time.Time `mapstructure:"birth_date" validate:"required"`
+	JoinDate      time.Time `mapstructure:"join_date" validate:"required"`
+	// Optional last day in company, person is not celebrated afterwards
+	LeaveDate         time.Time `mapstructure:"leave_date"`
 	LeadSlackMemberID *string   `mapstructure:"lead_slack_member_id" validate:"required"`
+	DisplayName       string    `mapstructure:"display_name"`
+	RealName          string    `mapstructure:"real_name"`
+	Email             string    `mapstructure:"email"`
+	Team              string    `mapstructure:"team"`
+	// Additional yearly dates (e.g. name_day) used by custom events
+	Dates   map[string]time.Time `mapstructure:"dates"`
+	Privacy Privacy              `mapstructure:"privacy"`
+}
+
+// Mention returns Slack mention of person, rendered by Slack as person's name
+func (p Person) Mention() string {
+	return fmt.Sprintf("<@%s>", p.SlackMemberID)
+}
+
+// Name returns display name, real name or Slack mention of person, whichever is known first
+func (p Person) Name() string {
+	switch {
+	case p.DisplayName != "":
+		return p.DisplayName
+	case p.RealName != "":
+		return p.RealName
+	}
+	return p.Mention()
+}
+
+// IsCelebrated returns false when person opted out of all celebrations
+func (p Person) IsCelebrated() bool {
+	return !p.Privacy.OptOut
+}
+
+// HasJoined returns true when person's join date is not after given day
+func (p Person) HasJoined(today time.Time) bool {
+	firstDay := time.Date(p.JoinDate.Year(), p.JoinDate.Month(), p.JoinDate.Day(), 0, 0, 0, 0, today.Location())
+	return !firstDay.After(today)
+}
+
+// HasLeft returns true when person's leave date is before given day
+func (p Person) HasLeft(today time.Time) bool {
+	if p.LeaveDate.IsZero() {
+		return false
+	}
+	lastDay := time.Date(p.LeaveDate.Year(), p.LeaveDate.Month(), p.LeaveDate.Day(), 0, 0, 0, 0, today.Location())
+	return lastDay.Before(today)
+}
+
+// AllowsChannelPosts returns false when person wants to be mentioned only in direct messages
+func (p Person) AllowsChannelPosts() bool {
+	return p.IsCelebrated() && !p.Privacy.DirectMessagesOnly
+}
+
+// AllowsPublicPosts returns false when person does not want to be mentioned on open channels
+func (p Person) AllowsPublicPosts() bool {
+	return p.AllowsChannelPosts() && !p.Privacy.NoPublicPosts
 }
 
+// Scopes of monthly report
+const (
+	CurrentMonthScope = "current_month"
+	NextMonthScope    = "next_month"
+	NextDaysScope     = "next_days"
+)
+
 type MonthlyReport struct {
-	Enabled         bool   `mapstructure:"enabled"`
-	ChannelName     string `mapstructure:"channel_name" validate:"required"`
-	MessageTemplate string `mapstructure:"message_template" validate:"required"`
+	Enabled                 bool   `mapstructure:"enabled"`
+	ChannelName             string `mapstructure:"channel_name" validate:"required"`
+	MessageTemplate         string `mapstructure:"message_template" validate:"required"`
+	BirthdayLineTemplate    string `mapstructure:"birthday_line_template"`
+	AnniversaryLineTemplate string `mapstructure:"anniversary_line_template"`
+	// Day of month report is sent on, defaults to 1
+	DayOfMonth int `mapstructure:"day_of_month" validate:"omitempty,min=1,max=28"`
+	// One of current_month (default), next_month or next_days
+	Scope     string `mapstructure:"scope" validate:"omitempty,oneof=current_month next_month next_days"`
+	DaysAhead int    `mapstructure:"days_ahead" validate:"required_if=Scope next_days,omitempty,min=1"`
+}
+
+// GetDayOfMonth returns day of month report is sent on
+func (r MonthlyReport) GetDayOfMonth() int {
+	if r.DayOfMonth == 0 {
+		return 1
+	}
+	return r.DayOfMonth
+}
+
+type WeeklyDigest struct {
+	Enabled                 bool   `mapstructure:"enabled"`
+	ChannelName             string `mapstructure:"channel_name" validate:"required_if=Enabled true"`
+	MessageTemplate         string `mapstructure:"message_template" validate:"required_if=Enabled true"`
+	BirthdayLineTemplate    string `mapstructure:"birthday_line_template"`
+	AnniversaryLineTemplate string `mapstructure:"anniversary_line_template"`
+	// Day of week digest is sent on (e.g. monday), defaults to monday
+	Weekday string `mapstructure:"weekday" validate:"omitempty,oneof=sunday monday tuesday wednesday thursday friday saturday"`
+	// Number of days covered by digest starting from the day it's sent, defaults to 7
+	DaysAhead int `mapstructure:"days_ahead" validate:"omitempty,min=1"`
+}
+
+// GetWeekday returns day of week digest is sent on
+func (d WeeklyDigest) GetWeekday() time.Weekday {
+	return parseWeekday(d.Weekday)
+}
+
+// GetDaysAhead returns number of days covered by digest
+func (d WeeklyDigest) GetDaysAhead() int {
+	return getDaysAheadOrDefault(d.DaysAhead)
+}
+
+// LeadDigest is sent to each lead as DM listing celebrations of their direct reports
+type LeadDigest struct {
+	Enabled                 bool   `mapstructure:"enabled"`
+	MessageTemplate         string `mapstructure:"message_template" validate:"required_if=Enabled true"`
+	BirthdayLineTemplate    string `mapstructure:"birthday_line_template"`
+	AnniversaryLineTemplate string `mapstructure:"anniversary_line_template"`
+	// Day of week digest is sent on (e.g. monday), defaults to monday
+	Weekday string `mapstructure:"weekday" validate:"omitempty,oneof=sunday monday tuesday wednesday thursday friday saturday"`
+	// Number of days covered by digest starting from the day it's sent, defaults to 7
+	DaysAhead int `mapstructure:"days_ahead" validate:"omitempty,min=1"`
+}
+
+// GetWeekday returns day of week digest is sent on
+func (d LeadDigest) GetWeekday() time.Weekday {
+	return parseWeekday(d.Weekday)
+}
+
+// GetDaysAhead returns number of days covered by digest
+func (d LeadDigest) GetDaysAhead() int {
+	return getDaysAheadOrDefault(d.DaysAhead)
+}
+
+// parseWeekday returns weekday of given name, monday by default
+func parseWeekday(name string) time.Weekday {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.EqualFold(wd.String(), name) {
+			return wd
+		}
+	}
+	return time.Monday
+}
+
+func getDaysAheadOrDefault(daysAhead int) int {
+	if daysAhead == 0 {
+		return 7
+	}
+	return daysAhead
 }
 
 type DownloadingUsers struct {
 	BirthdayCustomFieldName string `mapstructure:"birthday_custom_field_name" validate:"required"`
 	JoinDateCustomFieldName string `mapstructure:"join_date_custom_field_name" validate:"required"`
+	// Optional, users who have left before today are filtered out
+	LeaveDateCustomFieldName string   `mapstructure:"leave_date_custom_field_name"`
+	ExcludeGuests            bool     `mapstructure:"exclude_guests"`
+	RequireCustomFields      bool     `mapstructure:"require_custom_fields"`
+	IncludeEmailDomains      []string `mapstructure:"include_email_domains"`
+	ExcludeEmailDomains      []string `mapstructure:"exclude_email_domains"`
+	UserGroups               []string `mapstructure:"user_groups"`
 }
 
 type AnniversaryChannelReminder struct {
 	Enabled         bool   `mapstructure:"enabled"`
 	ChannelName     string `mapstructure:"channel_name" validate:"required"`
 	MessageTemplate string `mapstructure:"message_template" validate:"required"`
+	// Optional, same day anniversaries are posted as a single message listing lines of everyone
+	GroupedMessageTemplate string `mapstructure:"grouped_message_template"`
+	GroupedLineTemplate    string `mapstructure:"grouped_line_template" validate:"required_with=GroupedMessageTemplate"`
 }
 
 type BirthdaysChannelReminder struct {
 	Enabled         bool   `mapstructure:"enabled"`
 	ChannelName     string `mapstructure:"channel_name" validate:"required"`
 	MessageTemplate string `mapstructure:"message_template" validate:"required"`
+	// Optional, same day birthdays are posted as a single message listing lines of everyone
+	GroupedMessageTemplate string `mapstructure:"grouped_message_template"`
+	GroupedLineTemplate    string `mapstructure:"grouped_line_template" validate:"required_with=GroupedMessageTemplate"`
 }
 
 type BirthdaysPersonalReminder struct {
@@ -45,89 +205,280 @@ type BirthdaysPersonalReminder struct {
 	MessageTemplate string `mapstructure:"message_template" validate:"required"`
 }
 
+// Units of pre-reminder offsets
+const (
+	DaysUnit         = "days"
+	BusinessDaysUnit = "business_days"
+)
+
+type PreReminder struct {
+	DaysBefore      int    `mapstructure:"days_before" validate:"min=1"`
+	MessageTemplate string `mapstructure:"message_template" validate:"required"`
+	// One of days (default) or business_days (see calendar config)
+	Unit string `mapstructure:"unit" validate:"omitempty,oneof=days business_days"`
+}
+
 type BirthdaysDirectMessageReminder struct {
-	Enabled                    bool     `mapstructure:"enabled"`
-	MessageTemplate            string   `mapstructure:"message_template" validate:"required"`
-	PreReminderDaysBefore      int64    `mapstructure:"pre_reminder_days_before" validate:"required"`
-	PreRemidnerMessageTemplate string   `mapstructure:"pre_remidner_message_template" validate:"required"`
-	AlwaysNotifySlackIds       []string `mapstructure:"always_notify_slack_ids" validate:"required"`
+	Enabled                    bool          `mapstructure:"enabled"`
+	MessageTemplate            string        `mapstructure:"message_template" validate:"required"`
+	PreReminderDaysBefore      int64         `mapstructure:"pre_reminder_days_before" validate:"required_without=PreReminders"`
+	PreRemidnerMessageTemplate string        `mapstructure:"pre_remidner_message_template" validate:"required_with=PreReminderDaysBefore"`
+	PreReminders               []PreReminder `mapstructure:"pre_reminders" validate:"dive"`
+	AlwaysNotifySlackIds       []string      `mapstructure:"always_notify_slack_ids" validate:"required"`
+	// Number of managers notified walking up the lead chain (1 or 0 for direct lead only, 2 for lead of lead etc.)
+	NotifyManagementChainDepth int `mapstructure:"notify_management_chain_depth" validate:"omitempty,min=0"`
+}
+
+// FallbackRecipients are notified instead of lead when lead is missing or deactivated,
+// lead's lead is tried first, then HR partner of person's team and default recipient
+type FallbackRecipients struct {
+	DefaultSlackMemberID      string            `mapstructure:"default_slack_member_id"`
+	TeamHRPartners            map[string]string `mapstructure:"team_hr_partners"`
+	DeactivatedSlackMemberIDs []string          `mapstructure:"deactivated_slack_member_ids"`
+}
+
+type AnniversariesDirectMessageReminder struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Optional DM to leads on anniversary day
+	MessageTemplate            string        `mapstructure:"message_template"`
+	PreReminders               []PreReminder `mapstructure:"pre_reminders" validate:"dive"`
+	AlwaysNotifySlackIds       []string      `mapstructure:"always_notify_slack_ids"`
+	NotifyManagementChainDepth int           `mapstructure:"notify_management_chain_depth" validate:"omitempty,min=0"`
+}
+
+// CustomEvent is yearly celebration of date from person's `dates` (e.g. name day)
+type CustomEvent struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Name    string `mapstructure:"name" validate:"required"`
+	// Key of date in person's `dates`
+	DateField string `mapstructure:"date_field" validate:"required"`
+	// Optional channel post on the day
+	ChannelName     string `mapstructure:"channel_name" validate:"required_with=MessageTemplate"`
+	MessageTemplate string `mapstructure:"message_template" validate:"required_with=ChannelName"`
+	// Optional DMs to leads before the day
+	PreReminders []PreReminder `mapstructure:"pre_reminders" validate:"dive"`
+	// Optional lines of monthly report and weekly digest (available as `{{index .CustomEvents "<name>"}}`)
+	ReportLineTemplate string `mapstructure:"report_line_template"`
+}
+
+// CompanyEvent is celebration not tied to a person (e.g. office opening or company anniversary)
+type CompanyEvent struct {
+	Enabled bool      `mapstructure:"enabled"`
+	Name    string    `mapstructure:"name" validate:"required"`
+	Date    time.Time `mapstructure:"date" validate:"required"`
+	// Celebrated every year on date's day and month instead of once
+	Yearly          bool   `mapstructure:"yearly"`
+	ChannelName     string `mapstructure:"channel_name" validate:"required"`
+	MessageTemplate string `mapstructure:"message_template" validate:"required"`
+}
+
+type Milestone struct {
+	DaysAfter       int    `mapstructure:"days_after" validate:"min=1"`
+	MessageTemplate string `mapstructure:"message_template" validate:"required"`
+}
+
+// FarewellReminder uses leave date to say goodbye on the last day and remind leads before it
+type FarewellReminder struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Optional channel post on the last day
+	ChannelName     string `mapstructure:"channel_name" validate:"required_with=MessageTemplate"`
+	MessageTemplate string `mapstructure:"message_template" validate:"required_with=ChannelName"`
+	// Optional DMs to lead before the last day
+	PreReminders []PreReminder `mapstructure:"pre_reminders" validate:"dive"`
+}
+
+// Onboarding uses join date to welcome newcomers and remind their leads before start and at milestones
+type Onboarding struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Optional channel post on the first day
+	WelcomeChannelName     string `mapstructure:"welcome_channel_name" validate:"required_with=WelcomeMessageTemplate"`
+	WelcomeMessageTemplate string `mapstructure:"welcome_message_template" validate:"required_with=WelcomeChannelName"`
+	// Optional DMs to lead before the first day
+	PreStartReminders []PreReminder `mapstructure:"pre_start_reminders" validate:"dive"`
+	// Optional DMs to lead after the first day (e.g. 30, 60, 90 days or probation end)
+	Milestones []Milestone `mapstructure:"milestones" validate:"dive"`
+}
+
+// GetPreReminders returns pre_reminders along with the one given by pre_reminder_days_before
+func (r BirthdaysDirectMessageReminder) GetPreReminders() []PreReminder {
+	var preReminders []PreReminder
+	if r.PreReminderDaysBefore > 0 {
+		preReminders = append(preReminders, PreReminder{
+			DaysBefore:      int(r.PreReminderDaysBefore),
+			MessageTemplate: r.PreRemidnerMessageTemplate,
+		})
+	}
+	return append(preReminders, r.PreReminders...)
+}
+
+// Calendar defines working days used by offsets in business days
+type Calendar struct {
+	// Defaults to monday to friday
+	WorkingDays []string    `mapstructure:"working_days" validate:"dive,oneof=sunday monday tuesday wednesday thursday friday saturday"`
+	Holidays    []time.Time `mapstructure:"holidays"`
+}
+
+// IsWorkingDay returns true when date is one of working days and not a holiday
+func (c Calendar) IsWorkingDay(date time.Time) bool {
+	for _, h := range c.Holidays {
+		if h.Year() == date.Year() && h.YearDay() == date.YearDay() {
+			return false
+		}
+	}
+	if len(c.WorkingDays) == 0 {
+		return date.Weekday() != time.Saturday && date.Weekday() != time.Sunday
+	}
+	for _, wd := range c.WorkingDays {
+		if parseWeekday(wd) == date.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// SubtractBusinessDays returns date n working days before given date
+func (c Calendar) SubtractBusinessDays(date time.Time, n int) time.Time {
+	// Guard against calendar without any working days
+	for i := 0; n > 0 && i < 366*10; i++ {
+		date = date.AddDate(0, 0, -1)
+		if c.IsWorkingDay(date) {
+			n--
+		}
+	}
+	return date
+}
+
+type Retry struct {
+	MaxAttempts    int           `mapstructure:"max_attempts"`
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+}
+
+type Log struct {
+	Level  string `mapstructure:"level" validate:"omitempty,oneof=debug info warn error"`
+	Format string `mapstructure:"format" validate:"omitempty,oneof=text json"`
+}
+
+type HTTP struct {
+	APIURL   string        `mapstructure:"api_url"`
+	Proxy    string        `mapstructure:"proxy"`
+	CABundle string        `mapstructure:"ca_bundle"`
+	Timeout  time.Duration `mapstructure:"timeout"`
+}
+
+type Server struct {
+	ListenAddress   string `mapstructure:"listen_address"`
+	PreferencesFile string `mapstructure:"preferences_file"`
 }
 
 type Slack struct {
-	BotToken                       string
-	UserToken                      string
-	AnniversaryChannelReminder     AnniversaryChannelReminder     `mapstructure:"anniversary_channel_reminder" validate:"required"`
-	BirthdaysChannelReminder       BirthdaysChannelReminder       `mapstructure:"birthdays_channel_reminder" validate:"required"`
-	BirthdaysPersonalReminder      BirthdaysPersonalReminder      `mapstructure:"birthdays_personal_reminder" validate:"required"`
-	BirthdaysDirectMessageReminder BirthdaysDirectMessageReminder `mapstructure:"birthdays_direct_message_reminder" validate:"required"`
-	MonthlyReport                  MonthlyReport                  `mapstructure:"monthly_report" validate:"required"`
-	DownloadingUsers               DownloadingUsers               `mapstructure:"downloading_users" validate:"required"`
+	BotToken                           string
+	UserToken                          string
+	SigningSecret                      string
+	AnniversaryChannelReminder         AnniversaryChannelReminder         `mapstructure:"anniversary_channel_reminder" validate:"required"`
+	BirthdaysChannelReminder           BirthdaysChannelReminder           `mapstructure:"birthdays_channel_reminder" validate:"required"`
+	BirthdaysPersonalReminder          BirthdaysPersonalReminder          `mapstructure:"birthdays_personal_reminder" validate:"required"`
+	BirthdaysDirectMessageReminder     BirthdaysDirectMessageReminder     `mapstructure:"birthdays_direct_message_reminder" validate:"required"`
+	AnniversariesDirectMessageReminder AnniversariesDirectMessageReminder `mapstructure:"anniversaries_direct_message_reminder"`
+	CustomEvents                       []CustomEvent                      `mapstructure:"custom_events" validate:"dive"`
+	CompanyEvents                      []CompanyEvent                     `mapstructure:"company_events" validate:"dive"`
+	Onboarding                         Onboarding                         `mapstructure:"onboarding"`
+	FarewellReminder                   FarewellReminder                   `mapstructure:"farewell_reminder"`
+	MonthlyReport                      MonthlyReport                      `mapstructure:"monthly_report" validate:"required"`
+	WeeklyDigest                       WeeklyDigest                       `mapstructure:"weekly_digest"`
+	LeadDigest                         LeadDigest                         `mapstructure:"lead_digest"`
+	FallbackRecipients                 FallbackRecipients                 `mapstructure:"fallback_recipients"`
+	DownloadingUsers                   DownloadingUsers                   `mapstructure:"downloading_users" validate:"required"`
+	Retry                              Retry                              `mapstructure:"retry"`
+	HTTP                               HTTP                               `mapstructure:"http"`
 }
 
 type Config struct {
-	Slack  Slack    `mapstructure:"slack" validate:"required"`
-	People []Person `mapstructure:"people" validate:"required"`
+	Slack    Slack    `mapstructure:"slack" validate:"required"`
+	Calendar Calendar `mapstructure:"calendar"`
+	Server   Server   `mapstructure:"server"`
+	Log      Log      `mapstructure:"log"`
+	People   []Person `mapstructure:"people" validate:"required"`
 }
 
-func GetConfig() *Config {
+func GetConfig() (*Config, error) {
 	var c Config
 	if err := viper.Unmarshal(&c, viper.DecodeHook(
-		mapstructure.StringToTimeHookFunc(time.DateOnly),
+		mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeHookFunc(time.DateOnly),
+			mapstructure.StringToTimeDurationHookFunc(),
+		),
 	)); err != nil {
-		log.Fatalln("Error marshalling file:" + err.Error())
+		return nil, fmt.Errorf("Error marshalling file: %w", err)
 	}
-	return &c
+	return &c, nil
 }
 
-func InitConfig(filename string) {
+// InitConfig reads config file and validates it, returns error describing first problem found
+func InitConfig(filename string) error {
 	viper.SetConfigName(filename)
 	viper.AddConfigPath(".")
 	viper.SetConfigType("yml")
 
 	if err := viper.BindEnv("Slack.BotToken", "SLACK_BOT_TOKEN"); err != nil {
-		log.Fatalln("Error binding env vars:", err.Error())
+		return fmt.Errorf("Error binding env vars: %w", err)
 	}
 	if err := viper.BindEnv("Slack.UserToken", "SLACK_USER_TOKEN"); err != nil {
-		log.Fatalln("Error binding env vars:", err.Error())
+		return fmt.Errorf("Error binding env vars: %w", err)
+	}
+	if err := viper.BindEnv("Slack.SigningSecret", "SLACK_SIGNING_SECRET"); err != nil {
+		return fmt.Errorf("Error binding env vars: %w", err)
+	}
+	if err := viper.BindEnv("slack.http.api_url", "SLACK_API_URL"); err != nil {
+		return fmt.Errorf("Error binding env vars: %w", err)
 	}
 
 	if err := viper.ReadInConfig(); err != nil {
-		log.Fatalln("Error reading config file:" + err.Error())
+		return fmt.Errorf("Error reading config file: %w", err)
 	}
 
-	c := GetConfig()
+	c, err := GetConfig()
+	if err != nil {
+		return err
+	}
 
 	if err := validator.New(
 		validator.WithRequiredStructEnabled(),
 	).Struct(c); err != nil {
-		log.Fatalln("Missing required config attributes:" + err.Error())
+		return fmt.Errorf("Missing required config attributes: %w", err)
 	}
 
 	features_requiring_bot_token_are_enabled := false ||
 		c.Slack.AnniversaryChannelReminder.Enabled ||
 		c.Slack.BirthdaysChannelReminder.Enabled ||
 		c.Slack.BirthdaysDirectMessageReminder.Enabled ||
-		c.Slack.MonthlyReport.Enabled
+		c.Slack.AnniversariesDirectMessageReminder.Enabled ||
+		len(c.Slack.CustomEvents) > 0 ||
+		len(c.Slack.CompanyEvents) > 0 ||
+		c.Slack.Onboarding.Enabled ||
+		c.Slack.FarewellReminder.Enabled ||
+		c.Slack.MonthlyReport.Enabled ||
+		c.Slack.WeeklyDigest.Enabled ||
+		c.Slack.LeadDigest.Enabled
 
 	if features_requiring_bot_token_are_enabled && c.Slack.BotToken == "" {
-		log.Fatalln("Missing required environment variable: SLACK_BOT_TOKEN (required for enabled reminders)")
+		return errors.New("Missing required environment variable: SLACK_BOT_TOKEN (required for enabled reminders)")
 	}
 
 	if c.Slack.BirthdaysPersonalReminder.Enabled && c.Slack.UserToken == "" {
-		log.Fatalln("Missing required environment variable: SLACK_USER_TOKEN (required for enabled reminders)")
+		return errors.New("Missing required environment variable: SLACK_USER_TOKEN (required for enabled reminders)")
 	}
 
 	// Validate people as for some reason it's not done properly by validator
 	for _, p := range c.People {
 		if p.SlackMemberID == "" {
-			log.Println(p.SlackMemberID)
-			log.Fatalln("Missing slack_member_id")
+			return errors.New("Missing slack_member_id")
 		}
 		if p.BirthDate.IsZero() {
-			log.Fatalln("Missing birth date for slack_member_id: " + p.SlackMemberID)
+			return fmt.Errorf("Missing birth date of person with Slack ID %s", p.SlackMemberID)
 		}
 		if p.JoinDate.IsZero() {
-			log.Fatalln("Missing join date for slack_member_id: " + p.SlackMemberID)
+			return fmt.Errorf("Missing join date of person with Slack ID %s", p.SlackMemberID)
 		}
 	}
+	return nil
 }
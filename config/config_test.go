@@ -4,16 +4,95 @@ import (
 	"io"
 	"log"
 	"testing"
+	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestLoadingEnvVars(t *testing.T) {
 	log.SetOutput(io.Discard)
 
-	InitConfig("test_config")
-	c := GetConfig()
+	assert.NoError(t, InitConfig("test_config"))
+	c, err := GetConfig()
+	assert.NoError(t, err)
 
 	assert.True(t, c.Slack.BotToken == "test-bot-token")
 	assert.True(t, c.Slack.UserToken == "test-user-token")
 }
+
+func TestLoadingHTTPConfig(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	assert.NoError(t, InitConfig("test_config"))
+	c, err := GetConfig()
+	assert.NoError(t, err)
+
+	assert.Equal(t, "https://slack.example.com/api/", c.Slack.HTTP.APIURL)
+	assert.Equal(t, 10*time.Second, c.Slack.HTTP.Timeout)
+
+	t.Setenv("SLACK_API_URL", "http://localhost:8080/api/")
+	c, err = GetConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/api/", c.Slack.HTTP.APIURL, "Env var not overriding config")
+}
+
+func TestCalendar(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	assert.NoError(t, InitConfig("test_config"))
+	cfg, err := GetConfig()
+	assert.NoError(t, err)
+	c := cfg.Calendar
+
+	assert.Equal(t, []time.Time{time.Date(2016, time.December, 26, 0, 0, 0, 0, time.UTC)}, c.Holidays)
+
+	// Thursday is working day, Friday is not and Monday 26th is a holiday
+	thursday := time.Date(2016, time.December, 29, 0, 0, 0, 0, time.UTC)
+	assert.True(t, c.IsWorkingDay(thursday))
+	assert.False(t, c.IsWorkingDay(thursday.AddDate(0, 0, 1)))
+	assert.False(t, c.IsWorkingDay(thursday.AddDate(0, 0, -3)))
+	assert.Equal(t, time.Date(2016, time.December, 22, 0, 0, 0, 0, time.UTC), c.SubtractBusinessDays(thursday, 3))
+
+	assert.True(t, Calendar{}.IsWorkingDay(thursday.AddDate(0, 0, 1)), "Friday not working day by default")
+	assert.Equal(t, thursday, Calendar{}.SubtractBusinessDays(thursday.AddDate(0, 0, 4), 2))
+}
+
+func TestLoadingPersonDates(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	assert.NoError(t, InitConfig("test_config"))
+	c, err := GetConfig()
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]time.Time{"name_day": time.Date(2000, time.March, 19, 0, 0, 0, 0, time.UTC)}, c.People[1].Dates)
+}
+
+func TestHasLeft(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	assert.NoError(t, InitConfig("test_config"))
+	c, err := GetConfig()
+	assert.NoError(t, err)
+
+	assert.True(t, c.People[0].LeaveDate.IsZero())
+	assert.False(t, c.People[0].HasLeft(time.Date(2040, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	assert.False(t, c.People[1].HasLeft(time.Date(2030, time.December, 31, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, c.People[1].HasLeft(time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestHasJoined(t *testing.T) {
+	p := Person{JoinDate: time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)}
+
+	assert.False(t, p.HasJoined(time.Date(2016, time.May, 31, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, p.HasJoined(time.Date(2016, time.June, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, p.HasJoined(time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestAnniversaryDirectMessageWithoutPreReminders(t *testing.T) {
+	log.SetOutput(io.Discard)
+
+	r := AnniversariesDirectMessageReminder{Enabled: true, MessageTemplate: "<@%s> has %s in company today!"}
+
+	assert.NoError(t, validator.New(validator.WithRequiredStructEnabled()).Struct(r), "Day-of DM alone not valid")
+}
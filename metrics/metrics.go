@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const namespace = "celebrations"
+
+type handlerLabels struct {
+	handler string
+	backend string
+}
+
+// Registry keeps counters in memory and renders them in Prometheus text exposition format
+type Registry struct {
+	mu                sync.Mutex
+	eventsDetected    map[string]float64
+	messagesSent      map[handlerLabels]float64
+	failures          map[handlerLabels]float64
+	lastSuccessfulRun time.Time
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		eventsDetected: map[string]float64{},
+		messagesSent:   map[handlerLabels]float64{},
+		failures:       map[handlerLabels]float64{},
+	}
+}
+
+// Default is registry used by the app
+var Default = NewRegistry()
+
+func (r *Registry) EventDetected(eventType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.eventsDetected[eventType]++
+}
+
+func (r *Registry) MessageSent(handler string, backend string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messagesSent[handlerLabels{handler, backend}]++
+}
+
+func (r *Registry) Failure(handler string, backend string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failures[handlerLabels{handler, backend}]++
+}
+
+func (r *Registry) RunSucceeded(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastSuccessfulRun = t
+}
+
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var out string
+
+	out += header("events_detected_total", "counter", "Number of celebration events detected.")
+	eventTypes := make([]string, 0, len(r.eventsDetected))
+	for t := range r.eventsDetected {
+		eventTypes = append(eventTypes, t)
+	}
+	sort.Strings(eventTypes)
+	for _, t := range eventTypes {
+		out += fmt.Sprintf("%s_events_detected_total{event_type=%q} %g\n", namespace, t, r.eventsDetected[t])
+	}
+
+	out += header("messages_sent_total", "counter", "Number of messages sent by handler and backend.")
+	out += formatHandlerCounter("messages_sent_total", r.messagesSent)
+
+	out += header("failures_total", "counter", "Number of failed sends by handler and backend.")
+	out += formatHandlerCounter("failures_total", r.failures)
+
+	if !r.lastSuccessfulRun.IsZero() {
+		out += header("last_successful_run_timestamp_seconds", "gauge", "Unix time of the last run without failures.")
+		out += fmt.Sprintf("%s_last_successful_run_timestamp_seconds %d\n", namespace, r.lastSuccessfulRun.Unix())
+	}
+
+	_, err := io.WriteString(w, out)
+	return err
+}
+
+// WriteTextfile atomically writes metrics to file read by node-exporter textfile collector
+func (r *Registry) WriteTextfile(filename string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("Error creating metrics file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := r.Write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Error writing metrics file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Error writing metrics file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("Error writing metrics file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf("Error writing metrics file: %w", err)
+	}
+	return nil
+}
+
+func header(name string, metricType string, help string) string {
+	return fmt.Sprintf(
+		"# HELP %s_%s %s\n# TYPE %s_%s %s\n",
+		namespace, name, help,
+		namespace, name, metricType,
+	)
+}
+
+func formatHandlerCounter(name string, values map[handlerLabels]float64) string {
+	labels := make([]handlerLabels, 0, len(values))
+	for l := range values {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].handler == labels[j].handler {
+			return labels[i].backend < labels[j].backend
+		}
+		return labels[i].handler < labels[j].handler
+	})
+
+	var out string
+	for _, l := range labels {
+		out += fmt.Sprintf(
+			"%s_%s{handler=%q,backend=%q} %g\n",
+			namespace, name, l.handler, l.backend, values[l],
+		)
+	}
+	return out
+}
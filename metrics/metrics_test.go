@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	r := NewRegistry()
+	r.EventDetected("birthday")
+	r.EventDetected("birthday")
+	r.EventDetected("anniversary")
+	r.MessageSent("monthly_report", "slack")
+	r.Failure("birthdays_channel_reminder", "slack")
+
+	var out strings.Builder
+	assert.NoError(t, r.Write(&out))
+
+	assert.Contains(t, out.String(), `celebrations_events_detected_total{event_type="birthday"} 2`)
+	assert.Contains(t, out.String(), `celebrations_events_detected_total{event_type="anniversary"} 1`)
+	assert.Contains(t, out.String(), `celebrations_messages_sent_total{handler="monthly_report",backend="slack"} 1`)
+	assert.Contains(t, out.String(), `celebrations_failures_total{handler="birthdays_channel_reminder",backend="slack"} 1`)
+	assert.Contains(t, out.String(), "# TYPE celebrations_failures_total counter")
+	assert.NotContains(t, out.String(), "last_successful_run", "Run reported successful before it was")
+
+	r.RunSucceeded(time.Unix(1464739200, 0))
+
+	filename := filepath.Join(t.TempDir(), "celebrations.prom")
+	assert.NoError(t, r.WriteTextfile(filename))
+
+	bytes, err := os.ReadFile(filename)
+	assert.NoError(t, err)
+	assert.Contains(t, string(bytes), "celebrations_last_successful_run_timestamp_seconds 1464739200\n")
+}
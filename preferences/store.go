@@ -0,0 +1,110 @@
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/nomysz/celebrations/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Store keeps privacy preferences set by people themselves (by Slack member ID) in a local YAML file
+type Store struct {
+	mu          sync.Mutex
+	filename    string
+	preferences map[string]config.Privacy
+}
+
+// Load reads store from file; missing file results in an empty store
+func Load(filename string) (*Store, error) {
+	s := &Store{
+		filename:    filename,
+		preferences: map[string]config.Privacy{},
+	}
+
+	bytes, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, errors.New(
+			fmt.Sprintf("Error reading preferences file %s: %s", filename, err),
+		)
+	}
+
+	if err := yaml.Unmarshal(bytes, &s.preferences); err != nil {
+		return nil, errors.New(
+			fmt.Sprintf("Error unmarshalling preferences file %s: %s", filename, err),
+		)
+	}
+	if s.preferences == nil {
+		s.preferences = map[string]config.Privacy{}
+	}
+	return s, nil
+}
+
+func (s *Store) Get(slackMemberID string) (config.Privacy, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.preferences[slackMemberID]
+	return p, ok
+}
+
+// Set persists whole store with given preferences to file and stores them once written
+func (s *Store) Set(slackMemberID string, p config.Privacy) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	preferences := maps.Clone(s.preferences)
+	preferences[slackMemberID] = p
+
+	bytes, err := yaml.Marshal(preferences)
+	if err != nil {
+		return errors.New(
+			fmt.Sprintf("Error marshalling preferences: %s", err),
+		)
+	}
+	if err := writeFileAtomically(s.filename, bytes); err != nil {
+		return errors.New(
+			fmt.Sprintf("Error writing preferences file %s: %s", s.filename, err),
+		)
+	}
+	s.preferences = preferences
+	return nil
+}
+
+// writeFileAtomically writes to temporary file in the same directory and renames it, so the file
+// is never left partially written
+func writeFileAtomically(filename string, bytes []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(bytes); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// Apply overrides privacy of people with preferences found in store
+func (s *Store) Apply(people []config.Person) {
+	for i, p := range people {
+		if stored, ok := s.Get(p.SlackMemberID); ok {
+			people[i].Privacy = stored
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package preferences
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nomysz/celebrations/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Load(filepath.Join(dir, "missing.yml"))
+	assert.NoError(t, err, "Missing file not treated as empty store")
+	_, ok := s.Get("ID01")
+	assert.False(t, ok)
+
+	filename := filepath.Join(dir, "preferences.yml")
+	assert.NoError(t, os.WriteFile(filename, []byte("ID01:\n  opt_out: true\n  hide_age: true\n"), 0o600))
+	s, err = Load(filename)
+	assert.NoError(t, err)
+	p, ok := s.Get("ID01")
+	assert.True(t, ok)
+	assert.Equal(t, config.Privacy{OptOut: true, HideAge: true}, p)
+
+	assert.NoError(t, os.WriteFile(filename, []byte("ID01: [invalid"), 0o600))
+	_, err = Load(filename)
+	assert.ErrorContains(t, err, "Error unmarshalling preferences file")
+}
+
+func TestSet(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "preferences.yml")
+
+	s, err := Load(filename)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Set("ID01", config.Privacy{NoPublicPosts: true}))
+	assert.NoError(t, s.Set("ID02", config.Privacy{OptOut: true}))
+
+	persisted, err := Load(filename)
+	assert.NoError(t, err)
+	p, ok := persisted.Get("ID01")
+	assert.True(t, ok, "Preferences not persisted")
+	assert.Equal(t, config.Privacy{NoPublicPosts: true}, p)
+	p, ok = persisted.Get("ID02")
+	assert.True(t, ok, "Preferences not persisted")
+	assert.Equal(t, config.Privacy{OptOut: true}, p)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1, "Temporary file left behind")
+
+	s = &Store{filename: filepath.Join(dir, "missing", "preferences.yml"), preferences: map[string]config.Privacy{}}
+	assert.ErrorContains(t, s.Set("ID01", config.Privacy{}), "Error writing preferences file")
+	_, ok = s.Get("ID01")
+	assert.False(t, ok, "Preferences stored despite failed write")
+}
+
+func TestApply(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), "preferences.yml"))
+	assert.NoError(t, err)
+	assert.NoError(t, s.Set("ID01", config.Privacy{OptOut: true}))
+
+	people := []config.Person{
+		{SlackMemberID: "ID01", Privacy: config.Privacy{HideAge: true}},
+		{SlackMemberID: "ID02", Privacy: config.Privacy{HideAge: true}},
+	}
+	s.Apply(people)
+
+	assert.Equal(t, config.Privacy{OptOut: true}, people[0].Privacy, "Stored preferences not applied")
+	assert.Equal(t, config.Privacy{HideAge: true}, people[1].Privacy, "Preferences of person without stored ones changed")
+}
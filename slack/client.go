@@ -3,20 +3,90 @@ package slack
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/slack-go/slack"
 )
 
+const (
+	DefaultMaxAttempts    = 3
+	DefaultInitialBackoff = time.Second
+)
+
 type Client struct {
-	botToken  string
-	userToken string
+	botToken       string
+	userToken      string
+	apiURL         string
+	httpClient     *http.Client
+	bot            *slack.Client
+	user           *slack.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+	sleep          func(time.Duration)
+
+	mu sync.Mutex
+	// Channel IDs by name resolved by GetChannels (e.g. during preflight), reused when sending
+	channelIDs map[string]string
+}
+
+type Option func(*Client)
+
+// WithMaxAttempts sets how many times a single Slack call is attempted before giving up
+func WithMaxAttempts(maxAttempts int) Option {
+	return func(sc *Client) {
+		if maxAttempts > 0 {
+			sc.maxAttempts = maxAttempts
+		}
+	}
+}
+
+// WithInitialBackoff sets delay before the first retry of transient errors, doubled with every next attempt
+func WithInitialBackoff(backoff time.Duration) Option {
+	return func(sc *Client) {
+		if backoff > 0 {
+			sc.initialBackoff = backoff
+		}
+	}
+}
+
+// WithAPIURL sets Slack Web API base URL (default https://slack.com/api/), e.g. to point to a stand-in
+func WithAPIURL(apiURL string) Option {
+	return func(sc *Client) {
+		if apiURL != "" {
+			sc.apiURL = strings.TrimSuffix(apiURL, "/") + "/"
+		}
+	}
 }
 
-func NewClient(botToken string, userToken string) *Client {
-	return &Client{
-		botToken:  botToken,
-		userToken: userToken,
+func NewClient(botToken string, userToken string, options ...Option) *Client {
+	sc := &Client{
+		botToken:       botToken,
+		userToken:      userToken,
+		apiURL:         slack.APIURL,
+		httpClient:     http.DefaultClient,
+		maxAttempts:    DefaultMaxAttempts,
+		initialBackoff: DefaultInitialBackoff,
+		sleep:          time.Sleep,
+	}
+	for _, option := range options {
+		option(sc)
 	}
+	sc.bot = sc.newSlackClient(botToken)
+	sc.user = sc.newSlackClient(userToken)
+	return sc
+}
+
+func (sc *Client) newSlackClient(token string) *slack.Client {
+	return slack.New(
+		token,
+		slack.OptionAPIURL(sc.apiURL),
+		slack.OptionHTTPClient(sc.httpClient),
+	)
 }
 
 type ChannelMessenger interface {
@@ -37,15 +107,69 @@ type SlackCommunicator interface {
 	PersonalReminderSetter
 }
 
+// retry calls operation until it succeeds, fails with non transient error or max attempts are reached
+func (sc *Client) retry(operation func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := operation()
+		if err == nil {
+			return nil
+		}
+		delay, retryable := sc.getRetryDelay(err, attempt)
+		if !retryable || attempt >= sc.maxAttempts {
+			return err
+		}
+		sc.sleep(delay)
+	}
+}
+
+func (sc *Client) getRetryDelay(err error, attempt int) (time.Duration, bool) {
+	var rateLimitedErr *slack.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		return rateLimitedErr.RetryAfter, true
+	}
+
+	backoff := sc.initialBackoff * time.Duration(1<<(attempt-1))
+
+	var retryableErr interface{ Retryable() bool }
+	if errors.As(err, &retryableErr) {
+		return backoff, retryableErr.Retryable()
+	}
+
+	// Other network errors (e.g. invalid certificate or unknown host) are not going to fix themselves
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return backoff, true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return backoff, true
+	}
+
+	return 0, false
+}
+
+// getChannelID returns ID of channel resolved before or channel as given
+func (sc *Client) getChannelID(channel string) string {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if id, ok := sc.channelIDs[strings.TrimPrefix(channel, "#")]; ok {
+		return id
+	}
+	return channel
+}
+
 func (sc *Client) SendChannelMessage(channel string, msg string) error {
-	_, _, err := slack.New(sc.botToken).PostMessage(
-		channel,
-		slack.MsgOptionAttachments(
-			slack.Attachment{
-				Pretext: msg,
-			},
-		),
-	)
+	channelID := sc.getChannelID(channel)
+	err := sc.retry(func() error {
+		_, _, err := sc.bot.PostMessage(
+			channelID,
+			slack.MsgOptionAttachments(
+				slack.Attachment{
+					Pretext: msg,
+				},
+			),
+		)
+		return err
+	})
 
 	if err != nil {
 		return errors.New(
@@ -60,14 +184,18 @@ func (sc *Client) SendChannelMessage(channel string, msg string) error {
 }
 
 func (sc *Client) SendDirectMessage(slackId string, msg string) error {
-	api := slack.New(sc.botToken)
-
-	slack_ch, _, _, err := api.OpenConversation(
-		&slack.OpenConversationParameters{
-			Users:    []string{slackId},
-			ReturnIM: false,
-		},
-	)
+	var slack_ch *slack.Channel
+
+	err := sc.retry(func() error {
+		var err error
+		slack_ch, _, _, err = sc.bot.OpenConversation(
+			&slack.OpenConversationParameters{
+				Users:    []string{slackId},
+				ReturnIM: false,
+			},
+		)
+		return err
+	})
 
 	if err != nil {
 		return errors.New(
@@ -79,7 +207,10 @@ func (sc *Client) SendDirectMessage(slackId string, msg string) error {
 		)
 	}
 
-	_, _, err = api.PostMessage(slack_ch.ID, slack.MsgOptionText(msg, false))
+	err = sc.retry(func() error {
+		_, _, err := sc.bot.PostMessage(slack_ch.ID, slack.MsgOptionText(msg, false))
+		return err
+	})
 
 	if err != nil {
 		return errors.New(
@@ -94,11 +225,15 @@ func (sc *Client) SendDirectMessage(slackId string, msg string) error {
 }
 
 func (sc *Client) SetPersonalReminder(slackId string, time string, msg string) error {
-	_, err := slack.New(sc.userToken).AddUserReminder(
-		slackId,
-		msg,
-		time,
-	)
+	err := sc.retry(func() error {
+		_, err := sc.user.AddUserReminder(
+			slackId,
+			msg,
+			time,
+		)
+		return err
+	})
+
 	if err != nil {
 		return errors.New(
 			fmt.Sprintf(
@@ -0,0 +1,143 @@
+package slack
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTestClient(url string, delays *[]time.Duration) *Client {
+	sc := NewClient("bot-token", "user-token", WithMaxAttempts(3), WithAPIURL(url))
+	sc.sleep = func(d time.Duration) { *delays = append(*delays, d) }
+	return sc
+}
+
+func TestRetryOnRateLimit(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		if calls == 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"ok":true,"channel":"C01","ts":"1"}`))
+	}))
+	defer srv.Close()
+
+	var delays []time.Duration
+	sc := getTestClient(srv.URL, &delays)
+
+	assert.NoError(t, sc.SendChannelMessage("celebrations", "msg"))
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []time.Duration{7 * time.Second, 2 * DefaultInitialBackoff}, delays,
+		"Retry-After or backoff not honored")
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	var delays []time.Duration
+	sc := getTestClient(srv.URL, &delays)
+
+	assert.Error(t, sc.SetPersonalReminder("ID01", "3pm", "msg"))
+	assert.Equal(t, 3, calls)
+}
+
+func TestNoRetryOnPermanentError(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+	}))
+	defer srv.Close()
+
+	var delays []time.Duration
+	sc := getTestClient(srv.URL, &delays)
+
+	err := sc.SendChannelMessage("missing", "msg")
+	assert.ErrorContains(t, err, "channel_not_found")
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, delays)
+}
+
+func TestRetryOnConnectionRefused(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Close()
+
+	var delays []time.Duration
+	sc := getTestClient(srv.URL, &delays)
+
+	assert.ErrorContains(t, sc.SendChannelMessage("celebrations", "msg"), "connection refused")
+	assert.Len(t, delays, 2, "Connection refused not retried")
+}
+
+func TestNoRetryOnTLSError(t *testing.T) {
+	calls := 0
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer srv.Close()
+
+	var delays []time.Duration
+	sc := getTestClient(srv.URL, &delays)
+
+	assert.ErrorContains(t, sc.SendChannelMessage("celebrations", "msg"), "certificate")
+	assert.Equal(t, 0, calls)
+	assert.Empty(t, delays, "Certificate error retried")
+}
+
+func TestSendChannelMessageUsesResolvedChannelID(t *testing.T) {
+	var postedTo []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/conversations.list":
+			w.Write([]byte(`{"ok":true,"channels":[{"id":"C01","name":"celebrations","is_member":true}],"response_metadata":{"next_cursor":""}}`))
+		case "/chat.postMessage":
+			r.ParseForm()
+			postedTo = append(postedTo, r.Form.Get("channel"))
+			w.Write([]byte(`{"ok":true,"channel":"C01","ts":"1"}`))
+		}
+	}))
+	defer srv.Close()
+
+	var delays []time.Duration
+	sc := getTestClient(srv.URL, &delays)
+
+	assert.NoError(t, sc.SendChannelMessage("celebrations", "msg"))
+	_, err := sc.GetChannels()
+	assert.NoError(t, err)
+	assert.NoError(t, sc.SendChannelMessage("#celebrations", "msg"))
+	assert.NoError(t, sc.SendChannelMessage("leads", "msg"))
+
+	assert.Equal(t, []string{"celebrations", "C01", "leads"}, postedTo, "Resolved channel ID not reused")
+}
+
+func TestGetBotScopes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/auth.test", r.URL.Path)
+		assert.Equal(t, "Bearer bot-token", r.Header.Get("Authorization"))
+		w.Header().Set("X-OAuth-Scopes", "chat:write, im:write")
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	var delays []time.Duration
+	sc := getTestClient(srv.URL, &delays)
+
+	scopes, err := sc.GetBotScopes()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"chat:write", "im:write"}, scopes)
+}
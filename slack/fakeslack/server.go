@@ -0,0 +1,346 @@
+// Package fakeslack provides local stand-in of Slack Web API methods used by the app, for integration tests
+package fakeslack
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Message struct {
+	Token   string
+	Channel string
+	Text    string
+}
+
+type Reminder struct {
+	Token string
+	User  string
+	Text  string
+	Time  string
+}
+
+type User struct {
+	ID                string
+	DisplayName       string
+	RealName          string
+	Email             string
+	IsBot             bool
+	Deleted           bool
+	IsRestricted      bool
+	IsUltraRestricted bool
+	// Custom profile fields values by field ID
+	Fields map[string]string
+	// Slack error returned when downloading profile of user
+	ProfileError string
+}
+
+type UserGroup struct {
+	ID      string
+	Handle  string
+	Members []string
+}
+
+type Channel struct {
+	ID        string
+	Name      string
+	IsPrivate bool
+	IsMember  bool
+}
+
+// Server records messages and reminders sent to it and serves configured users and channels
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	messages  []Message
+	reminders []Reminder
+	users     []User
+	groups    []UserGroup
+	channels  []Channel
+	scopes    []string
+	errors    map[string]string
+	limits    map[string]int
+	calls     map[string]int
+}
+
+func New() *Server {
+	s := &Server{
+		errors: map[string]string{},
+		limits: map[string]int{},
+		calls:  map[string]int{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auth.test", s.authTest)
+	mux.HandleFunc("/chat.postMessage", s.chatPostMessage)
+	mux.HandleFunc("/conversations.list", s.conversationsList)
+	mux.HandleFunc("/conversations.open", s.conversationsOpen)
+	mux.HandleFunc("/reminders.add", s.remindersAdd)
+	mux.HandleFunc("/users.list", s.usersList)
+	mux.HandleFunc("/users.profile.get", s.usersProfileGet)
+	mux.HandleFunc("/usergroups.list", s.usergroupsList)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := strings.TrimPrefix(r.URL.Path, "/")
+
+		s.mu.Lock()
+		s.calls[method]++
+		limited := s.limits[method] > 0
+		if limited {
+			s.limits[method]--
+		}
+		s.mu.Unlock()
+
+		if limited {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	return s
+}
+
+// APIURL returns base URL to be used by Slack client instead of https://slack.com/api/
+func (s *Server) APIURL() string {
+	return s.URL + "/"
+}
+
+func (s *Server) AddUser(u User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users = append(s.users, u)
+}
+
+func (s *Server) AddUserGroup(g UserGroup) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups = append(s.groups, g)
+}
+
+func (s *Server) AddChannel(ch Channel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels = append(s.channels, ch)
+}
+
+// SetScopes sets scopes reported by auth.test for every token
+func (s *Server) SetScopes(scopes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scopes = scopes
+}
+
+// SetError makes every call of given API method (e.g. chat.postMessage) fail with given Slack error
+func (s *Server) SetError(method string, slackError string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[method] = slackError
+}
+
+// SetRateLimit makes next n calls of given API method respond with HTTP 429
+func (s *Server) SetRateLimit(method string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits[method] = n
+}
+
+// Calls returns number of calls of given API method, including rate limited ones
+func (s *Server) Calls(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[method]
+}
+
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message{}, s.messages...)
+}
+
+func (s *Server) Reminders() []Reminder {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Reminder{}, s.reminders...)
+}
+
+func getToken(r *http.Request) string {
+	if token := r.FormValue("token"); token != "" {
+		return token
+	}
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// writeResponse writes Slack style JSON response or configured error for API method
+func (s *Server) writeResponse(w http.ResponseWriter, r *http.Request, body map[string]any) {
+	method := strings.TrimPrefix(r.URL.Path, "/")
+
+	s.mu.Lock()
+	slackError, failing := s.errors[method]
+	s.mu.Unlock()
+
+	if failing {
+		body = map[string]any{"ok": false, "error": slackError}
+	} else {
+		body["ok"] = true
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+func (s *Server) authTest(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	w.Header().Set("X-OAuth-Scopes", strings.Join(s.scopes, ","))
+	s.mu.Unlock()
+	s.writeResponse(w, r, map[string]any{"user_id": "UBOT"})
+}
+
+func (s *Server) chatPostMessage(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+
+	text := r.FormValue("text")
+	if attachments := r.FormValue("attachments"); attachments != "" {
+		var parsed []struct {
+			Pretext string `json:"pretext"`
+		}
+		json.Unmarshal([]byte(attachments), &parsed)
+		for _, a := range parsed {
+			text += a.Pretext
+		}
+	}
+
+	s.mu.Lock()
+	_, failing := s.errors["chat.postMessage"]
+	if !failing {
+		s.messages = append(s.messages, Message{
+			Token:   getToken(r),
+			Channel: r.FormValue("channel"),
+			Text:    text,
+		})
+	}
+	s.mu.Unlock()
+
+	s.writeResponse(w, r, map[string]any{"channel": r.FormValue("channel"), "ts": "1"})
+}
+
+func (s *Server) conversationsList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	channels := []map[string]any{}
+	for _, ch := range s.channels {
+		channels = append(channels, map[string]any{
+			"id":         ch.ID,
+			"name":       ch.Name,
+			"is_private": ch.IsPrivate,
+			"is_member":  ch.IsMember,
+		})
+	}
+	s.mu.Unlock()
+
+	s.writeResponse(w, r, map[string]any{
+		"channels":          channels,
+		"response_metadata": map[string]any{"next_cursor": ""},
+	})
+}
+
+func (s *Server) conversationsOpen(w http.ResponseWriter, r *http.Request) {
+	s.writeResponse(w, r, map[string]any{
+		"channel": map[string]any{"id": "D" + r.FormValue("users")},
+	})
+}
+
+func (s *Server) remindersAdd(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	_, failing := s.errors["reminders.add"]
+	if !failing {
+		s.reminders = append(s.reminders, Reminder{
+			Token: getToken(r),
+			User:  r.FormValue("user"),
+			Text:  r.FormValue("text"),
+			Time:  r.FormValue("time"),
+		})
+	}
+	s.mu.Unlock()
+
+	s.writeResponse(w, r, map[string]any{"reminder": map[string]any{"id": "Rm01"}})
+}
+
+// usersList serves users page by page using numeric cursor
+func (s *Server) usersList(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.FormValue("limit"))
+	offset, _ := strconv.Atoi(r.FormValue("cursor"))
+
+	s.mu.Lock()
+	end := len(s.users)
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	members := []map[string]any{}
+	for _, u := range s.users[min(offset, len(s.users)):end] {
+		members = append(members, map[string]any{
+			"id":                  u.ID,
+			"is_bot":              u.IsBot,
+			"deleted":             u.Deleted,
+			"is_restricted":       u.IsRestricted,
+			"is_ultra_restricted": u.IsUltraRestricted,
+			"profile": map[string]any{
+				"display_name": u.DisplayName,
+				"real_name":    u.RealName,
+				"email":        u.Email,
+			},
+		})
+	}
+	nextCursor := ""
+	if end < len(s.users) {
+		nextCursor = strconv.Itoa(end)
+	}
+	s.mu.Unlock()
+
+	s.writeResponse(w, r, map[string]any{
+		"members":           members,
+		"response_metadata": map[string]any{"next_cursor": nextCursor},
+	})
+}
+
+func (s *Server) usersProfileGet(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	profile := map[string]any{}
+	for _, u := range s.users {
+		if u.ID != r.FormValue("user") {
+			continue
+		}
+		if u.ProfileError != "" {
+			s.mu.Unlock()
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]any{"ok": false, "error": u.ProfileError})
+			return
+		}
+		fields := map[string]any{}
+		for id, value := range u.Fields {
+			fields[id] = map[string]any{"value": value, "alt": ""}
+		}
+		profile = map[string]any{"display_name": u.DisplayName, "fields": fields}
+	}
+	s.mu.Unlock()
+
+	s.writeResponse(w, r, map[string]any{"profile": profile})
+}
+
+func (s *Server) usergroupsList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	groups := []map[string]any{}
+	for _, g := range s.groups {
+		groups = append(groups, map[string]any{
+			"id":     g.ID,
+			"handle": g.Handle,
+			"users":  g.Members,
+		})
+	}
+	s.mu.Unlock()
+
+	s.writeResponse(w, r, map[string]any{"usergroups": groups})
+}
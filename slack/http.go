@@ -0,0 +1,60 @@
+package slack
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// NewHTTPClient returns HTTP client using given proxy (defaults to HTTPS_PROXY env vars), additional CA certificates (PEM bundle) and timeout
+func NewHTTPClient(proxyURL string, caBundle string, timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return nil, errors.New(
+				fmt.Sprintf("Error parsing proxy URL %s: %s", proxyURL, err),
+			)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	if caBundle != "" {
+		pem, err := os.ReadFile(caBundle)
+		if err != nil {
+			return nil, errors.New(
+				fmt.Sprintf("Error reading CA bundle %s: %s", caBundle, err),
+			)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New(
+				fmt.Sprintf("No certificates found in CA bundle %s", caBundle),
+			)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
+
+// WithHTTPClient sets HTTP client used for all Slack calls
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(sc *Client) {
+		if httpClient != nil {
+			sc.httpClient = httpClient
+		}
+	}
+}
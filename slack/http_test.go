@@ -0,0 +1,56 @@
+package slack
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPClientUsesProxy(t *testing.T) {
+	var requested string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.String()
+		w.Write([]byte(`{"ok":true,"channel":"C01","ts":"1"}`))
+	}))
+	defer proxy.Close()
+
+	httpClient, err := NewHTTPClient(proxy.URL, "", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, httpClient.Timeout)
+
+	sc := NewClient("bot-token", "user-token", WithAPIURL("http://slack.invalid/api"), WithHTTPClient(httpClient))
+
+	assert.NoError(t, sc.SendChannelMessage("celebrations", "msg"))
+	assert.Equal(t, "http://slack.invalid/api/chat.postMessage", requested)
+}
+
+func TestHTTPClientTrustsCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":true,"channel":"C01","ts":"1"}`))
+	}))
+	defer srv.Close()
+
+	sc := NewClient("bot-token", "user-token", WithAPIURL(srv.URL), WithMaxAttempts(1))
+	assert.ErrorContains(t, sc.SendChannelMessage("celebrations", "msg"), "certificate")
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	assert.NoError(t, os.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: srv.Certificate().Raw,
+	}), 0o600))
+
+	httpClient, err := NewHTTPClient("", caBundle, 0)
+	assert.NoError(t, err)
+
+	sc = NewClient("bot-token", "user-token", WithAPIURL(srv.URL), WithHTTPClient(httpClient))
+	assert.NoError(t, sc.SendChannelMessage("celebrations", "msg"))
+
+	_, err = NewHTTPClient("", filepath.Join(t.TempDir(), "missing.pem"), 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,119 @@
+package slack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+type Channel struct {
+	ID        string
+	Name      string
+	IsPrivate bool
+	IsMember  bool
+}
+
+// Inspector provides information needed to verify setup before sending anything
+type Inspector interface {
+	GetChannels() ([]Channel, error)
+	GetBotScopes() ([]string, error)
+	GetUserScopes() ([]string, error)
+}
+
+// GetChannels returns all public channels and private channels visible to the bot, remembering their IDs
+// to be used when sending channel messages
+func (sc *Client) GetChannels() ([]Channel, error) {
+	var channels []Channel
+	params := &slack.GetConversationsParameters{
+		ExcludeArchived: true,
+		Limit:           1000,
+		Types:           []string{"public_channel", "private_channel"},
+	}
+
+	for {
+		var page []slack.Channel
+		var cursor string
+		err := sc.retry(func() error {
+			var err error
+			page, cursor, err = sc.bot.GetConversations(params)
+			return err
+		})
+		if err != nil {
+			return nil, errors.New(
+				fmt.Sprintf("Error listing Slack channels: %s", err),
+			)
+		}
+
+		for _, ch := range page {
+			channels = append(channels, Channel{
+				ID:        ch.ID,
+				Name:      ch.Name,
+				IsPrivate: ch.IsPrivate,
+				IsMember:  ch.IsMember,
+			})
+		}
+
+		if cursor == "" {
+			sc.mu.Lock()
+			sc.channelIDs = map[string]string{}
+			for _, ch := range channels {
+				sc.channelIDs[ch.Name] = ch.ID
+			}
+			sc.mu.Unlock()
+			return channels, nil
+		}
+		params.Cursor = cursor
+	}
+}
+
+func (sc *Client) GetBotScopes() ([]string, error) {
+	return sc.getScopes(sc.botToken)
+}
+
+func (sc *Client) GetUserScopes() ([]string, error) {
+	return sc.getScopes(sc.userToken)
+}
+
+// getScopes calls auth.test and reads scopes granted to token from X-OAuth-Scopes header
+func (sc *Client) getScopes(token string) ([]string, error) {
+	req, err := http.NewRequest(http.MethodPost, sc.apiURL+"auth.test", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := sc.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.New(
+			fmt.Sprintf("Error calling Slack auth.test: %s", err),
+		)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, errors.New(
+			fmt.Sprintf("Error decoding Slack auth.test response: %s", err),
+		)
+	}
+	if !body.OK {
+		return nil, errors.New(
+			fmt.Sprintf("Error calling Slack auth.test: %s", body.Error),
+		)
+	}
+
+	var scopes []string
+	for _, s := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes, nil
+}
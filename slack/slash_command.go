@@ -0,0 +1,54 @@
+package slack
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/slack-go/slack"
+)
+
+type SlashCommand struct {
+	Command string
+	UserID  string
+	Text    string
+}
+
+// ParseSlashCommand verifies request signature using Slack signing secret and parses slash command payload
+func ParseSlashCommand(r *http.Request, signingSecret string) (SlashCommand, error) {
+	verifier, err := slack.NewSecretsVerifier(r.Header, signingSecret)
+	if err != nil {
+		return SlashCommand{}, errors.New(
+			fmt.Sprintf("Error verifying Slack request: %s", err),
+		)
+	}
+
+	body, err := io.ReadAll(io.TeeReader(r.Body, &verifier))
+	if err != nil {
+		return SlashCommand{}, errors.New(
+			fmt.Sprintf("Error reading Slack request: %s", err),
+		)
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	if err := verifier.Ensure(); err != nil {
+		return SlashCommand{}, errors.New(
+			fmt.Sprintf("Error verifying Slack request: %s", err),
+		)
+	}
+
+	sc, err := slack.SlashCommandParse(r)
+	if err != nil {
+		return SlashCommand{}, errors.New(
+			fmt.Sprintf("Error parsing Slack slash command: %s", err),
+		)
+	}
+
+	return SlashCommand{
+		Command: sc.Command,
+		UserID:  sc.UserID,
+		Text:    sc.Text,
+	}, nil
+}
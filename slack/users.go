@@ -0,0 +1,113 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+const usersPageSize = 200
+
+type User struct {
+	ID          string
+	DisplayName string
+	RealName    string
+	Email       string
+	IsBot       bool
+	Deleted     bool
+	// Guests (multi-channel and single-channel)
+	IsRestricted      bool
+	IsUltraRestricted bool
+}
+
+// GetUsers lists all workspace users page by page, calling onPage with number of users listed so far
+func (sc *Client) GetUsers(ctx context.Context, onPage func(listed int)) ([]User, error) {
+	var users []User
+
+	p := sc.bot.GetUsersPaginated(slack.GetUsersOptionLimit(usersPageSize))
+	for {
+		var next slack.UserPagination
+		err := sc.retry(func() error {
+			var err error
+			next, err = p.Next(ctx)
+			return err
+		})
+		if p.Done(err) {
+			return users, nil
+		}
+		if err != nil {
+			return users, errors.New(
+				fmt.Sprintf("Error listing Slack users: %s", err),
+			)
+		}
+		p = next
+
+		for _, u := range p.Users {
+			users = append(users, User{
+				ID:                u.ID,
+				DisplayName:       u.Profile.DisplayName,
+				RealName:          u.Profile.RealName,
+				Email:             u.Profile.Email,
+				IsBot:             u.IsBot,
+				Deleted:           u.Deleted,
+				IsRestricted:      u.IsRestricted,
+				IsUltraRestricted: u.IsUltraRestricted,
+			})
+		}
+		if onPage != nil {
+			onPage(len(users))
+		}
+	}
+}
+
+// GetUserProfileFields returns values of custom profile fields by field ID
+func (sc *Client) GetUserProfileFields(ctx context.Context, slackId string) (map[string]string, error) {
+	var profile *slack.UserProfile
+	err := sc.retry(func() error {
+		var err error
+		profile, err = sc.bot.GetUserProfileContext(
+			ctx,
+			&slack.GetUserProfileParameters{UserID: slackId, IncludeLabels: false},
+		)
+		return err
+	})
+	if err != nil {
+		return nil, errors.New(
+			fmt.Sprintf("Error downloading profile of user with Slack ID %s: %s", slackId, err),
+		)
+	}
+
+	fields := map[string]string{}
+	for id, f := range profile.Fields.ToMap() {
+		fields[id] = f.Value
+	}
+	return fields, nil
+}
+
+// GetUserGroupMembers returns IDs of members of user group given by ID or handle (e.g. engineering)
+func (sc *Client) GetUserGroupMembers(ctx context.Context, userGroup string) ([]string, error) {
+	var groups []slack.UserGroup
+	err := sc.retry(func() error {
+		var err error
+		groups, err = sc.bot.GetUserGroupsContext(ctx, slack.GetUserGroupsOptionIncludeUsers(true))
+		return err
+	})
+	if err != nil {
+		return nil, errors.New(
+			fmt.Sprintf("Error listing Slack user groups: %s", err),
+		)
+	}
+
+	handle := strings.TrimPrefix(userGroup, "@")
+	for _, g := range groups {
+		if g.ID == userGroup || g.Handle == handle {
+			return g.Users, nil
+		}
+	}
+	return nil, errors.New(
+		fmt.Sprintf("Slack user group %s not found", userGroup),
+	)
+}